@@ -0,0 +1,562 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package benchcmp parses and compares the output of Go benchmarks,
+// as produced by the testing package.
+package benchcmp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BenchCmp is a pair of benchmarks.
+type BenchCmp struct {
+	Before *Bench
+	After  *Bench
+}
+
+// Correlate correlates benchmarks from two BenchSets.
+// Benchmarks that appear in only one of the sets are ignored
+// without warning; use Unmatched to find them.
+func Correlate(before, after BenchSet) (cmps []BenchCmp, warnings []string) {
+	cmps = make([]BenchCmp, 0, len(after))
+	for name, beforebb := range before {
+		afterbb, ok := after[name]
+		if !ok {
+			continue
+		}
+		if len(beforebb) != len(afterbb) {
+			warnings = append(warnings, fmt.Sprintf("ignoring %s: before has %d instances, after has %d", name, len(beforebb), len(afterbb)))
+			continue
+		}
+		for i, beforeb := range beforebb {
+			afterb := afterbb[i]
+			cmps = append(cmps, BenchCmp{beforeb, afterb})
+		}
+	}
+	return
+}
+
+// Unmatched returns the names of the benchmarks that appear only in
+// before and only in after, sorted. These are the benchmarks that
+// Correlate ignores silently, as when a benchmark is renamed.
+func Unmatched(before, after BenchSet) (onlyBefore, onlyAfter []string) {
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			onlyBefore = append(onlyBefore, name)
+		}
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			onlyAfter = append(onlyAfter, name)
+		}
+	}
+	sort.Strings(onlyBefore)
+	sort.Strings(onlyAfter)
+	return
+}
+
+// Rename returns a copy of bb in which each benchmark whose name is a
+// key of names is renamed to the corresponding value, so that it can be
+// correlated with a benchmark of the new name. Benchmarks renamed to an
+// existing name follow it.
+func Rename(bb BenchSet, names map[string]string) BenchSet {
+	renamed := make(BenchSet, len(bb))
+	var moved []string
+	for name, b := range bb {
+		if _, ok := names[name]; ok {
+			moved = append(moved, name)
+			continue
+		}
+		renamed[name] = b
+	}
+	sort.Strings(moved)
+	for _, name := range moved {
+		to := names[name]
+		for _, b := range bb[name] {
+			c := *b
+			c.Name = to
+			renamed[to] = append(renamed[to], &c)
+		}
+	}
+	return renamed
+}
+
+// NormalizeName returns a key for fuzzy matching of the benchmark name:
+// the name in lower case, with each run of the separators '_', '/' and '-'
+// replaced by a single '_'.
+func NormalizeName(name string) string {
+	var key []byte
+	sep := false
+	for _, c := range []byte(strings.ToLower(name)) {
+		if c == '_' || c == '/' || c == '-' {
+			sep = true
+			continue
+		}
+		if sep {
+			key = append(key, '_')
+			sep = false
+		}
+		key = append(key, c)
+	}
+	if sep {
+		key = append(key, '_')
+	}
+	return string(key)
+}
+
+// FuzzyRenames returns renames, for use with Rename, of the benchmarks
+// that appear only in before to those that appear only in after with the
+// same NormalizeName key, so that exact matches always take precedence.
+// A key shared by more than one such benchmark on either side is
+// ambiguous, and is not matched. The warnings describe every fuzzy match
+// and every ambiguity, so that they can be checked.
+func FuzzyRenames(before, after BenchSet) (renames map[string]string, warnings []string) {
+	onlyBefore, onlyAfter := Unmatched(before, after)
+	byKey := func(names []string) map[string][]string {
+		m := make(map[string][]string)
+		for _, name := range names {
+			key := NormalizeName(name)
+			m[key] = append(m[key], name)
+		}
+		return m
+	}
+	oldKeys, newKeys := byKey(onlyBefore), byKey(onlyAfter)
+	renames = make(map[string]string)
+	for _, name := range onlyBefore {
+		key := NormalizeName(name)
+		olds, news := oldKeys[key], newKeys[key]
+		switch {
+		case len(news) == 0:
+			continue
+		case len(olds) > 1 || len(news) > 1:
+			if olds[0] == name {
+				warnings = append(warnings, fmt.Sprintf("ignoring fuzzy match of %s to %s: ambiguous", strings.Join(olds, ", "), strings.Join(news, ", ")))
+			}
+		default:
+			renames[name] = news[0]
+			warnings = append(warnings, fmt.Sprintf("fuzzy match: %s as %s", name, news[0]))
+		}
+	}
+	return renames, warnings
+}
+
+// SplitName splits a benchmark name into its base name and the
+// GOMAXPROCS value that testing.B appends to it, as in
+// "BenchmarkFoo-8". Since testing.B omits the suffix when GOMAXPROCS
+// is 1, a name without a numeric suffix is returned whole, with procs 1.
+func SplitName(s string) (base string, procs int) {
+	i := strings.LastIndex(s, "-")
+	if i < 0 {
+		return s, 1
+	}
+	n, err := strconv.Atoi(s[i+1:])
+	if err != nil || n <= 0 || s[i+1] == '+' {
+		return s, 1
+	}
+	return s[:i], n
+}
+
+// BenchCmpN is a series of runs of a benchmark, in the order
+// in which their BenchSets were given to CorrelateN.
+type BenchCmpN struct {
+	Benches []*Bench
+}
+
+// CorrelateN correlates benchmarks across several BenchSets.
+// Only benchmarks that appear the same number of times in every
+// set are correlated. Sets are numbered from 1 in warnings.
+func CorrelateN(sets []BenchSet) (cmps []BenchCmpN, warnings []string) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, set := range sets {
+		for name := range set {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+Names:
+	for _, name := range names {
+		var missing []string
+		for i, set := range sets {
+			if len(set[name]) == 0 {
+				missing = append(missing, strconv.Itoa(i+1))
+			}
+		}
+		switch len(missing) {
+		case 0:
+		case 1:
+			warnings = append(warnings, fmt.Sprintf("ignoring %s: missing from file %s", name, missing[0]))
+			continue Names
+		default:
+			warnings = append(warnings, fmt.Sprintf("ignoring %s: missing from files %s", name, strings.Join(missing, ", ")))
+			continue Names
+		}
+		first := sets[0][name]
+		for i, set := range sets[1:] {
+			if len(set[name]) != len(first) {
+				warnings = append(warnings, fmt.Sprintf("ignoring %s: file 1 has %d instances, file %d has %d", name, len(first), i+2, len(set[name])))
+				continue Names
+			}
+		}
+		for i := range first {
+			cmp := BenchCmpN{Benches: make([]*Bench, len(sets))}
+			for j, set := range sets {
+				cmp.Benches[j] = set[name][i]
+			}
+			cmps = append(cmps, cmp)
+		}
+	}
+	return
+}
+
+// Name returns the name of the benchmark.
+func (c BenchCmp) Name() string   { return c.Before.Name }
+func (c BenchCmp) String() string { return fmt.Sprintf("<%s, %s>", c.Before, c.After) }
+
+// Measured reports whether both benchmarks recorded
+// the measurement indicated by flag.
+func (c BenchCmp) Measured(flag int) bool { return c.Before.Measured&c.After.Measured&flag != 0 }
+
+// DeltaNsOp, DeltaMbS, DeltaBOp, and DeltaAllocsOp return the
+// Delta for the corresponding measurement.
+func (c BenchCmp) DeltaNsOp() Delta { return Delta{c.Before.NsOp, c.After.NsOp} }
+func (c BenchCmp) DeltaMbS() Delta  { return Delta{c.Before.MbS, c.After.MbS} }
+func (c BenchCmp) DeltaBOp() Delta  { return Delta{float64(c.Before.BOp), float64(c.After.BOp)} }
+func (c BenchCmp) DeltaAllocsOp() Delta {
+	return Delta{float64(c.Before.AllocsOp), float64(c.After.AllocsOp)}
+}
+
+// Delta returns the Delta for the measurement indicated by flag,
+// which must be one of NsOp, MbS, BOp, or AllocsOp.
+func (c BenchCmp) Delta(flag int) Delta {
+	return Delta{c.Before.value(flag), c.After.value(flag)}
+}
+
+// MeasuredExtra reports whether both benchmarks recorded
+// the extra measurement with the given unit.
+func (c BenchCmp) MeasuredExtra(unit string) bool {
+	_, before := c.Before.Extra[unit]
+	_, after := c.After.Extra[unit]
+	return before && after
+}
+
+// DeltaExtra returns the Delta for the extra measurement with the given unit.
+func (c BenchCmp) DeltaExtra(unit string) Delta {
+	return Delta{c.Before.Extra[unit], c.After.Extra[unit]}
+}
+
+// PValue returns the p-value of Welch's t-test on the samples of the
+// measurement with the given unit. It reports false if either side
+// has fewer than two samples.
+func (c BenchCmp) PValue(unit string) (float64, bool) {
+	return c.PValueFunc(unit, WelchTTest)
+}
+
+// PValueFunc is like PValue but uses test, such as WelchTTest or
+// MannWhitneyUTest, to compare the samples. It reports false if
+// test fails.
+func (c BenchCmp) PValueFunc(unit string, test func(x, y []float64) (stat, p float64, err error)) (float64, bool) {
+	_, p, err := test(c.Before.Samples[unit], c.After.Samples[unit])
+	if err != nil {
+		return 0, false
+	}
+	return p, true
+}
+
+// GeoMean returns the geometric mean of the ratios After/Before of the
+// measurement indicated by flag. Benchmarks that did not record the
+// measurement, or for which either value is zero, are skipped.
+// GeoMean returns 1 if no benchmarks are included.
+func GeoMean(cmps []BenchCmp, flag int) float64 {
+	mean, _ := GeoMeanFunc(cmps, func(c BenchCmp) (Delta, bool) {
+		return c.Delta(flag), c.Measured(flag)
+	})
+	return mean
+}
+
+// GeoMeanFunc is like GeoMean, but calls measure to find the Delta
+// for each benchmark and whether it was recorded. It also returns
+// the number of benchmarks included in the mean.
+func GeoMeanFunc(cmps []BenchCmp, measure func(BenchCmp) (Delta, bool)) (mean float64, n int) {
+	var sum float64
+	for _, cmp := range cmps {
+		d, ok := measure(cmp)
+		if !ok || d.Before == 0 || d.After == 0 {
+			continue
+		}
+		sum += math.Log(d.After / d.Before)
+		n++
+	}
+	if n == 0 {
+		return 1, 0
+	}
+	return math.Exp(sum / float64(n)), n
+}
+
+// WeightedGeoMeanFunc is like GeoMeanFunc, but weights the log of the
+// ratio of each benchmark by weight(cmp), so that the mean is
+//
+//	exp(Σ wᵢ·log(Afterᵢ/Beforeᵢ) / Σ wᵢ)
+//
+// With the weights all equal, it is the plain geometric mean; with each
+// the total time of a benchmark, its iterations times ns/op, it is the
+// mean as it bears on the time spent running them all. Benchmarks with
+// a weight that is not positive are skipped.
+func WeightedGeoMeanFunc(cmps []BenchCmp, measure func(BenchCmp) (Delta, bool), weight func(BenchCmp) float64) (mean float64, n int) {
+	var sum, total float64
+	for _, cmp := range cmps {
+		d, ok := measure(cmp)
+		if !ok || d.Before == 0 || d.After == 0 {
+			continue
+		}
+		w := weight(cmp)
+		if !(w > 0) || math.IsInf(w, 0) {
+			continue
+		}
+		sum += w * math.Log(d.After/d.Before)
+		total += w
+		n++
+	}
+	if n == 0 {
+		return 1, 0
+	}
+	return math.Exp(sum / total), n
+}
+
+// Name returns the name of the benchmark.
+func (c BenchCmpN) Name() string { return c.Benches[0].Name }
+
+// Measured reports whether every run recorded the measurement.
+func (c BenchCmpN) Measured(flag int) bool {
+	for _, b := range c.Benches {
+		if b.Measured&flag == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Span returns a BenchCmp of the first and last runs.
+func (c BenchCmpN) Span() BenchCmp {
+	return BenchCmp{c.Benches[0], c.Benches[len(c.Benches)-1]}
+}
+
+// Delta is the before and after value for a benchmark measurement.
+// Both must be non-negative.
+type Delta struct {
+	Before float64
+	After  float64
+}
+
+// mag calculates the magnitude of a change, regardless of the direction of
+// the change. mag is intended for sorting and has no independent meaning.
+func (d Delta) mag() float64 {
+	switch {
+	case d.Before != 0 && d.After != 0 && d.Before >= d.After:
+		return d.After / d.Before
+	case d.Before != 0 && d.After != 0 && d.Before < d.After:
+		return d.Before / d.After
+	case d.Before == 0 && d.After == 0:
+		return 1
+	default:
+		// 0 -> 1 or 1 -> 0
+		// These are significant changes and worth surfacing.
+		return math.Inf(1)
+	}
+}
+
+// Changed reports whether the benchmark quantities are different.
+// It is ChangedBy(0).
+func (d Delta) Changed() bool { return d.Before != d.After }
+
+// ChangedBy reports whether the benchmark quantities differ by more
+// than pct percent of Before, in either direction. A change from zero
+// is beyond any threshold.
+func (d Delta) ChangedBy(pct float64) bool {
+	if !d.Changed() {
+		return false
+	}
+	if d.Before == 0 {
+		return true
+	}
+	return math.Abs(100*(d.After-d.Before)/d.Before) > pct
+}
+
+// Float64 returns After / Before. If Before is 0, Float64 returns
+// 1 if After is also 0, and +Inf otherwise.
+func (d Delta) Float64() float64 {
+	switch {
+	case d.Before != 0:
+		return d.After / d.Before
+	case d.After == 0:
+		return 1
+	default:
+		return math.Inf(1)
+	}
+}
+
+// Percent formats a Delta as a percent change, ranging from -100% up.
+// A change from zero to a non-zero value has no meaningful percentage
+// and is formatted as "?".
+func (d Delta) Percent() string {
+	if d.Before == 0 && d.After != 0 {
+		return "?"
+	}
+	return fmt.Sprintf("%+.2f%%", 100*d.Float64()-100)
+}
+
+// Multiple formats a Delta as a multiplier, ranging from 0.00x up.
+// Like Percent, a change from zero is formatted as "?".
+func (d Delta) Multiple() string {
+	return d.MultipleN(2)
+}
+
+// MultipleN is like Multiple, but formats the multiplier with the given
+// number of decimal places, as 1.043x with 3. The places apply however
+// large the multiplier, so that a large speedup is as wide as it needs
+// to be and no wider: 1234.5x with 1.
+func (d Delta) MultipleN(places int) string {
+	if d.Before == 0 && d.After != 0 {
+		return "?"
+	}
+	return fmt.Sprintf("%.*fx", places, d.Float64())
+}
+
+// String returns a debugging representation of d.
+func (d Delta) String() string {
+	return fmt.Sprintf("Δ(%f, %f)", d.Before, d.After)
+}
+
+// ByParseOrder sorts BenchCmps to match the order in
+// which the Before benchmarks were presented to Parse.
+type ByParseOrder []BenchCmp
+
+func (x ByParseOrder) Len() int           { return len(x) }
+func (x ByParseOrder) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
+func (x ByParseOrder) Less(i, j int) bool { return x[i].Before.ord < x[j].Before.ord }
+
+// ByName sorts BenchCmps alphabetically by benchmark name.
+// Benchmarks with the same name remain in parse order.
+type ByName []BenchCmp
+
+func (x ByName) Len() int      { return len(x) }
+func (x ByName) Swap(i, j int) { x[i], x[j] = x[j], x[i] }
+func (x ByName) Less(i, j int) bool {
+	if x[i].Name() != x[j].Name() {
+		return x[i].Name() < x[j].Name()
+	}
+	return x[i].Before.ord < x[j].Before.ord
+}
+
+// lessByDelta provides lexicographic ordering:
+//   * largest delta by magnitude
+//   * alphabetic by name
+func lessByDelta(i, j BenchCmp, calcDelta func(BenchCmp) Delta) bool {
+	iDelta, jDelta := calcDelta(i).mag(), calcDelta(j).mag()
+	if iDelta != jDelta {
+		return iDelta < jDelta
+	}
+	return i.Name() < j.Name()
+}
+
+// ByDeltaNsOp sorts BenchCmps lexicographically by change
+// in ns/op, descending, then by benchmark name.
+type ByDeltaNsOp []BenchCmp
+
+func (x ByDeltaNsOp) Len() int           { return len(x) }
+func (x ByDeltaNsOp) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
+func (x ByDeltaNsOp) Less(i, j int) bool { return lessByDelta(x[i], x[j], BenchCmp.DeltaNsOp) }
+
+// ByDeltaMbS sorts BenchCmps lexicographically by change
+// in MB/s, descending, then by benchmark name.
+type ByDeltaMbS []BenchCmp
+
+func (x ByDeltaMbS) Len() int           { return len(x) }
+func (x ByDeltaMbS) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
+func (x ByDeltaMbS) Less(i, j int) bool { return lessByDelta(x[i], x[j], BenchCmp.DeltaMbS) }
+
+// ByDeltaBOp sorts BenchCmps lexicographically by change
+// in B/op, descending, then by benchmark name.
+type ByDeltaBOp []BenchCmp
+
+func (x ByDeltaBOp) Len() int           { return len(x) }
+func (x ByDeltaBOp) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
+func (x ByDeltaBOp) Less(i, j int) bool { return lessByDelta(x[i], x[j], BenchCmp.DeltaBOp) }
+
+// ByDeltaAllocsOp sorts BenchCmps lexicographically by change
+// in allocs/op, descending, then by benchmark name.
+type ByDeltaAllocsOp []BenchCmp
+
+func (x ByDeltaAllocsOp) Len() int           { return len(x) }
+func (x ByDeltaAllocsOp) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
+func (x ByDeltaAllocsOp) Less(i, j int) bool { return lessByDelta(x[i], x[j], BenchCmp.DeltaAllocsOp) }
+
+// ByDelta sorts BenchCmps lexicographically by change in the
+// measurement returned by Delta, descending, then by Tie or,
+// if Tie is nil, by benchmark name.
+type ByDelta struct {
+	Cmps  []BenchCmp
+	Delta func(BenchCmp) Delta
+	Tie   func(i, j BenchCmp) bool // orders benchmarks with the same change
+}
+
+func (x ByDelta) Len() int      { return len(x.Cmps) }
+func (x ByDelta) Swap(i, j int) { x.Cmps[i], x.Cmps[j] = x.Cmps[j], x.Cmps[i] }
+func (x ByDelta) Less(i, j int) bool {
+	a, b := x.Cmps[i], x.Cmps[j]
+	if x.Tie != nil && x.Delta(a).mag() == x.Delta(b).mag() {
+		return x.Tie(a, b)
+	}
+	return lessByDelta(a, b, x.Delta)
+}
+
+// lessBySignedDelta provides lexicographic ordering:
+//   * largest increase, down to largest decrease
+//   * alphabetic by name
+func lessBySignedDelta(i, j BenchCmp, calcDelta func(BenchCmp) Delta) bool {
+	iDelta, jDelta := calcDelta(i).Float64(), calcDelta(j).Float64()
+	if iDelta != jDelta {
+		return iDelta > jDelta
+	}
+	return i.Name() < j.Name()
+}
+
+// BySignedDeltaNsOp sorts BenchCmps lexicographically by change in
+// ns/op, from the largest increase to the largest decrease, then by
+// benchmark name. Unlike ByDeltaNsOp, it puts all slowdowns first.
+type BySignedDeltaNsOp []BenchCmp
+
+func (x BySignedDeltaNsOp) Len() int      { return len(x) }
+func (x BySignedDeltaNsOp) Swap(i, j int) { x[i], x[j] = x[j], x[i] }
+func (x BySignedDeltaNsOp) Less(i, j int) bool {
+	return lessBySignedDelta(x[i], x[j], BenchCmp.DeltaNsOp)
+}
+
+// BySignedDelta sorts BenchCmps lexicographically by change in the
+// measurement returned by Delta, from the largest increase to the
+// largest decrease, then by Tie or, if Tie is nil, by benchmark name.
+type BySignedDelta struct {
+	Cmps  []BenchCmp
+	Delta func(BenchCmp) Delta
+	Tie   func(i, j BenchCmp) bool // orders benchmarks with the same change
+}
+
+func (x BySignedDelta) Len() int      { return len(x.Cmps) }
+func (x BySignedDelta) Swap(i, j int) { x.Cmps[i], x.Cmps[j] = x.Cmps[j], x.Cmps[i] }
+func (x BySignedDelta) Less(i, j int) bool {
+	a, b := x.Cmps[i], x.Cmps[j]
+	if x.Tie != nil && x.Delta(a).Float64() == x.Delta(b).Float64() {
+		return x.Tie(a, b)
+	}
+	return lessBySignedDelta(a, b, x.Delta)
+}
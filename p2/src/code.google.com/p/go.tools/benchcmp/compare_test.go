@@ -0,0 +1,470 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchcmp
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestDelta(t *testing.T) {
+	cases := []struct {
+		before  float64
+		after   float64
+		mag     float64
+		f       float64
+		changed bool
+		pct     string
+		mult    string
+	}{
+		{before: 1, after: 1, mag: 1, f: 1, changed: false, pct: "+0.00%", mult: "1.00x"},
+		{before: 1, after: 2, mag: 0.5, f: 2, changed: true, pct: "+100.00%", mult: "2.00x"},
+		{before: 2, after: 1, mag: 0.5, f: 0.5, changed: true, pct: "-50.00%", mult: "0.50x"},
+		{before: 0, after: 0, mag: 1, f: 1, changed: false, pct: "+0.00%", mult: "1.00x"},
+		{before: 1, after: 0, mag: math.Inf(1), f: 0, changed: true, pct: "-100.00%", mult: "0.00x"},
+		{before: 0, after: 1, mag: math.Inf(1), f: math.Inf(1), changed: true, pct: "?", mult: "?"},
+		{before: 0, after: 0.5, mag: math.Inf(1), f: math.Inf(1), changed: true, pct: "?", mult: "?"},
+		{before: 0.5, after: 0, mag: math.Inf(1), f: 0, changed: true, pct: "-100.00%", mult: "0.00x"},
+	}
+	for _, tt := range cases {
+		d := Delta{tt.before, tt.after}
+		if want, have := tt.mag, d.mag(); want != have {
+			t.Errorf("%s.mag(): want %f have %f", d, want, have)
+		}
+		if want, have := tt.f, d.Float64(); want != have {
+			t.Errorf("%s.Float64(): want %f have %f", d, want, have)
+		}
+		if want, have := tt.changed, d.Changed(); want != have {
+			t.Errorf("%s.Changed(): want %t have %t", d, want, have)
+		}
+		if want, have := tt.pct, d.Percent(); want != have {
+			t.Errorf("%s.Percent(): want %q have %q", d, want, have)
+		}
+		if want, have := tt.mult, d.Multiple(); want != have {
+			t.Errorf("%s.Multiple(): want %q have %q", d, want, have)
+		}
+	}
+}
+
+func TestDeltaMultipleN(t *testing.T) {
+	cases := []struct {
+		before, after float64
+		places        int
+		want          string
+	}{
+		{before: 100, after: 104, places: 2, want: "1.04x"},
+		{before: 100, after: 104.3, places: 3, want: "1.043x"},
+		{before: 100, after: 104, places: 0, want: "1x"},
+		{before: 100, after: 98, places: 2, want: "0.98x"},
+		{before: 100, after: 98, places: 1, want: "1.0x"},
+		{before: 2, after: 7, places: 2, want: "3.50x"},
+		{before: 2, after: 7, places: 1, want: "3.5x"},
+		{before: 1, after: 1234.5, places: 1, want: "1234.5x"},
+		{before: 0, after: 1, places: 3, want: "?"},
+	}
+	for _, tt := range cases {
+		d := Delta{tt.before, tt.after}
+		if have := d.MultipleN(tt.places); have != tt.want {
+			t.Errorf("%s.MultipleN(%d): want %q have %q", d, tt.places, tt.want, have)
+		}
+	}
+}
+
+func TestDeltaChangedBy(t *testing.T) {
+	cases := []struct {
+		before, after, pct float64
+		want               bool
+	}{
+		{before: 100, after: 100, pct: 0, want: false},
+		{before: 100, after: 101, pct: 0, want: true},
+		{before: 100, after: 105, pct: 5, want: false},
+		{before: 100, after: 106, pct: 5, want: true},
+		{before: 100, after: 94, pct: 5, want: true},
+		{before: 100, after: 96, pct: 5, want: false},
+		{before: 0, after: 0, pct: 5, want: false},
+		{before: 0, after: 1, pct: 1000, want: true},
+		{before: 1, after: 0, pct: 99, want: true},
+		{before: 1, after: 0, pct: 100, want: false},
+	}
+	for _, tt := range cases {
+		d := Delta{tt.before, tt.after}
+		if have := d.ChangedBy(tt.pct); have != tt.want {
+			t.Errorf("%s.ChangedBy(%v): want %t have %t", d, tt.pct, tt.want, have)
+		}
+		if tt.pct == 0 && d.ChangedBy(0) != d.Changed() {
+			t.Errorf("%s.ChangedBy(0) differs from Changed()", d)
+		}
+	}
+}
+
+func TestCorrelate(t *testing.T) {
+	// Benches that are going to be successfully correlated get N thus:
+	//   0x<counter><num benches><b = before | a = after>
+	// Read this: "<counter> of <num benches>, from <before|after>".
+	before := BenchSet{
+		"BenchmarkOneEach":   []*Bench{{Name: "BenchmarkOneEach", N: 0x11b}},
+		"BenchmarkOneToNone": []*Bench{{Name: "BenchmarkOneToNone"}},
+		"BenchmarkOneToTwo":  []*Bench{{Name: "BenchmarkOneToTwo"}},
+		"BenchmarkTwoToOne": []*Bench{
+			{Name: "BenchmarkTwoToOne"},
+			{Name: "BenchmarkTwoToOne"},
+		},
+		"BenchmarkTwoEach": []*Bench{
+			{Name: "BenchmarkTwoEach", N: 0x12b},
+			{Name: "BenchmarkTwoEach", N: 0x22b},
+		},
+	}
+
+	after := BenchSet{
+		"BenchmarkOneEach":   []*Bench{{Name: "BenchmarkOneEach", N: 0x11a}},
+		"BenchmarkNoneToOne": []*Bench{{Name: "BenchmarkNoneToOne"}},
+		"BenchmarkTwoToOne":  []*Bench{{Name: "BenchmarkTwoToOne"}},
+		"BenchmarkOneToTwo": []*Bench{
+			{Name: "BenchmarkOneToTwo"},
+			{Name: "BenchmarkOneToTwo"},
+		},
+		"BenchmarkTwoEach": []*Bench{
+			{Name: "BenchmarkTwoEach", N: 0x12a},
+			{Name: "BenchmarkTwoEach", N: 0x22a},
+		},
+	}
+
+	pairs, errs := Correlate(before, after)
+
+	// Fail to match: BenchmarkOneToTwo, BenchmarkTwoToOne.
+	// BenchmarkOneToNone and BenchmarkNoneToOne are left to Unmatched.
+	if len(errs) != 2 {
+		t.Errorf("Correlated expected 2 errors, got %d: %v", len(errs), errs)
+	}
+
+	// Want three correlated pairs: one BenchmarkOneEach, two BenchmarkTwoEach.
+	if len(pairs) != 3 {
+		t.Fatalf("Correlated expected 3 pairs, got %v", pairs)
+	}
+
+	for _, pair := range pairs {
+		if pair.Before.N&0xF != 0xb {
+			t.Errorf("unexpected Before in pair %s", pair)
+		}
+		if pair.After.N&0xF != 0xa {
+			t.Errorf("unexpected After in pair %s", pair)
+		}
+		if pair.Before.N>>4 != pair.After.N>>4 {
+			t.Errorf("mismatched pair %s", pair)
+		}
+	}
+}
+
+func TestUnmatched(t *testing.T) {
+	before := BenchSet{
+		"BenchmarkBoth":     []*Bench{{Name: "BenchmarkBoth"}},
+		"BenchmarkOldB":     []*Bench{{Name: "BenchmarkOldB"}},
+		"BenchmarkOldA":     []*Bench{{Name: "BenchmarkOldA"}},
+		"BenchmarkTwoToOne": []*Bench{{Name: "BenchmarkTwoToOne"}, {Name: "BenchmarkTwoToOne"}},
+	}
+	after := BenchSet{
+		"BenchmarkBoth":     []*Bench{{Name: "BenchmarkBoth"}},
+		"BenchmarkNew":      []*Bench{{Name: "BenchmarkNew"}},
+		"BenchmarkTwoToOne": []*Bench{{Name: "BenchmarkTwoToOne"}},
+	}
+
+	onlyBefore, onlyAfter := Unmatched(before, after)
+	if want := []string{"BenchmarkOldA", "BenchmarkOldB"}; !reflect.DeepEqual(want, onlyBefore) {
+		t.Errorf("Unmatched only before: want %v have %v", want, onlyBefore)
+	}
+	if want := []string{"BenchmarkNew"}; !reflect.DeepEqual(want, onlyAfter) {
+		t.Errorf("Unmatched only after: want %v have %v", want, onlyAfter)
+	}
+}
+
+func TestRename(t *testing.T) {
+	bb := BenchSet{
+		"BenchmarkOldName": []*Bench{{Name: "BenchmarkOldName", N: 1}},
+		"BenchmarkKept":    []*Bench{{Name: "BenchmarkKept", N: 2}},
+		"BenchmarkMerged":  []*Bench{{Name: "BenchmarkMerged", N: 3}},
+	}
+	renamed := Rename(bb, map[string]string{
+		"BenchmarkOldName": "BenchmarkNewName",
+		"BenchmarkMerged":  "BenchmarkKept",
+		"BenchmarkAbsent":  "BenchmarkIgnored",
+	})
+
+	want := BenchSet{
+		"BenchmarkNewName": []*Bench{{Name: "BenchmarkNewName", N: 1}},
+		"BenchmarkKept":    []*Bench{{Name: "BenchmarkKept", N: 2}, {Name: "BenchmarkKept", N: 3}},
+	}
+	if !reflect.DeepEqual(want, renamed) {
+		t.Errorf("Rename: want %v have %v", want, renamed)
+	}
+	if bb["BenchmarkOldName"][0].Name != "BenchmarkOldName" {
+		t.Errorf("Rename modified its argument")
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	cases := []struct {
+		name, want string
+	}{
+		{name: "BenchmarkFoo_Bar", want: "benchmarkfoo_bar"},
+		{name: "BenchmarkFoo/Bar", want: "benchmarkfoo_bar"},
+		{name: "benchmarkfoo-bar", want: "benchmarkfoo_bar"},
+		{name: "BenchmarkFoo__/-Bar-8", want: "benchmarkfoo_bar_8"},
+		{name: "BenchmarkFoo/", want: "benchmarkfoo_"},
+	}
+	for _, tt := range cases {
+		if have := NormalizeName(tt.name); tt.want != have {
+			t.Errorf("NormalizeName(%q): want %q have %q", tt.name, tt.want, have)
+		}
+	}
+}
+
+func TestFuzzyRenames(t *testing.T) {
+	set := func(names ...string) BenchSet {
+		bb := make(BenchSet)
+		for i, name := range names {
+			bb.Add(&Bench{Name: name}, i)
+		}
+		return bb
+	}
+	before := set("BenchmarkFoo_Bar", "BenchmarkExact", "BenchmarkExact_Too", "BenchmarkA_B", "BenchmarkA-B", "BenchmarkGone")
+	after := set("BenchmarkFoo/Bar", "BenchmarkExact", "BenchmarkExact/Too", "BenchmarkExact_Too", "BenchmarkA/B")
+
+	renames, warnings := FuzzyRenames(before, after)
+	// BenchmarkExact_Too matches exactly, so is not renamed, and
+	// BenchmarkExact/Too is left alone. Both BenchmarkA_B and
+	// BenchmarkA-B normalize to the key of BenchmarkA/B.
+	want := map[string]string{"BenchmarkFoo_Bar": "BenchmarkFoo/Bar"}
+	if !reflect.DeepEqual(want, renames) {
+		t.Errorf("FuzzyRenames: want %v have %v", want, renames)
+	}
+	wantWarnings := []string{
+		"ignoring fuzzy match of BenchmarkA-B, BenchmarkA_B to BenchmarkA/B: ambiguous",
+		"fuzzy match: BenchmarkFoo_Bar as BenchmarkFoo/Bar",
+	}
+	if !reflect.DeepEqual(wantWarnings, warnings) {
+		t.Errorf("FuzzyRenames warnings:\nwant %q\nhave %q", wantWarnings, warnings)
+	}
+
+	// Ambiguity in the new names is not matched either.
+	renames, _ = FuzzyRenames(set("BenchmarkX_Y"), set("BenchmarkX/Y", "BenchmarkX-Y"))
+	if len(renames) != 0 {
+		t.Errorf("FuzzyRenames of ambiguous new names: want none have %v", renames)
+	}
+}
+
+func TestCorrelateN(t *testing.T) {
+	sets := []BenchSet{
+		{
+			"BenchmarkAll":     []*Bench{{Name: "BenchmarkAll", N: 1}},
+			"BenchmarkNotLast": []*Bench{{Name: "BenchmarkNotLast"}},
+			"BenchmarkTwice": []*Bench{
+				{Name: "BenchmarkTwice", N: 1},
+				{Name: "BenchmarkTwice", N: 2},
+			},
+			"BenchmarkUneven": []*Bench{{Name: "BenchmarkUneven"}},
+		},
+		{
+			"BenchmarkAll":     []*Bench{{Name: "BenchmarkAll", N: 1}},
+			"BenchmarkNotLast": []*Bench{{Name: "BenchmarkNotLast"}},
+			"BenchmarkTwice": []*Bench{
+				{Name: "BenchmarkTwice", N: 1},
+				{Name: "BenchmarkTwice", N: 2},
+			},
+			"BenchmarkUneven": []*Bench{
+				{Name: "BenchmarkUneven"},
+				{Name: "BenchmarkUneven"},
+			},
+		},
+		{
+			"BenchmarkAll": []*Bench{{Name: "BenchmarkAll", N: 1}},
+			"BenchmarkTwice": []*Bench{
+				{Name: "BenchmarkTwice", N: 1},
+				{Name: "BenchmarkTwice", N: 2},
+			},
+			"BenchmarkUneven": []*Bench{{Name: "BenchmarkUneven"}},
+			"BenchmarkLast":   []*Bench{{Name: "BenchmarkLast"}},
+		},
+	}
+
+	cmps, warnings := CorrelateN(sets)
+
+	wantWarnings := []string{
+		"ignoring BenchmarkLast: missing from files 1, 2",
+		"ignoring BenchmarkNotLast: missing from file 3",
+		"ignoring BenchmarkUneven: file 1 has 1 instances, file 2 has 2",
+	}
+	if !reflect.DeepEqual(wantWarnings, warnings) {
+		t.Errorf("CorrelateN warnings: want %q have %q", wantWarnings, warnings)
+	}
+
+	// Want three correlated series: one BenchmarkAll, two BenchmarkTwice.
+	if len(cmps) != 3 {
+		t.Fatalf("CorrelateN expected 3 series, got %v", cmps)
+	}
+	for _, cmp := range cmps {
+		if len(cmp.Benches) != len(sets) {
+			t.Fatalf("series %s has %d runs, want %d", cmp.Name(), len(cmp.Benches), len(sets))
+		}
+		for _, b := range cmp.Benches {
+			if b.Name != cmp.Name() || b.N != cmp.Benches[0].N {
+				t.Errorf("mismatched series %v", cmp.Benches)
+			}
+		}
+	}
+
+	// A benchmark missing from several files names all of them.
+	gappy := BenchSet{"BenchmarkGappy": []*Bench{{Name: "BenchmarkGappy"}}}
+	_, warnings = CorrelateN([]BenchSet{gappy, {}, gappy, {}})
+	wantWarnings = []string{"ignoring BenchmarkGappy: missing from files 2, 4"}
+	if !reflect.DeepEqual(wantWarnings, warnings) {
+		t.Errorf("CorrelateN warnings with gaps: want %q have %q", wantWarnings, warnings)
+	}
+}
+
+func TestGeoMean(t *testing.T) {
+	c := []BenchCmp{
+		{&Bench{NsOp: 10, MbS: 1, Measured: NsOp | MbS}, &Bench{NsOp: 20, MbS: 4, Measured: NsOp | MbS}},
+		{&Bench{NsOp: 10, MbS: 1, Measured: NsOp | MbS}, &Bench{NsOp: 5, MbS: 1, Measured: NsOp | MbS}},
+		{&Bench{NsOp: 4, MbS: 0, Measured: NsOp | MbS}, &Bench{NsOp: 36, MbS: 7, Measured: NsOp | MbS}},
+		{&Bench{NsOp: 0, AllocsOp: 3, Measured: NsOp | AllocsOp}, &Bench{NsOp: 5, AllocsOp: 3, Measured: NsOp | AllocsOp}},
+	}
+	cases := []struct {
+		flag int
+		want float64
+		n    int
+	}{
+		{flag: NsOp, want: math.Cbrt(2 * 0.5 * 9), n: 3},
+		{flag: MbS, want: 2, n: 2},
+		{flag: AllocsOp, want: 1, n: 1},
+		{flag: BOp, want: 1, n: 0},
+	}
+	for _, tt := range cases {
+		if have := GeoMean(c, tt.flag); math.Abs(have-tt.want) > 1e-9 {
+			t.Errorf("GeoMean(%d): want %f have %f", tt.flag, tt.want, have)
+		}
+		flag := tt.flag
+		measure := func(c BenchCmp) (Delta, bool) { return c.Delta(flag), c.Measured(flag) }
+		if _, have := GeoMeanFunc(c, measure); have != tt.n {
+			t.Errorf("GeoMean(%d) included %d benchmarks, want %d", tt.flag, have, tt.n)
+		}
+	}
+}
+
+func TestWeightedGeoMean(t *testing.T) {
+	c := []BenchCmp{
+		{&Bench{N: 1000, NsOp: 10, Measured: NsOp}, &Bench{N: 1000, NsOp: 20, Measured: NsOp}},
+		{&Bench{N: 10, NsOp: 3000, Measured: NsOp}, &Bench{N: 10, NsOp: 1500, Measured: NsOp}},
+		{&Bench{N: 0, NsOp: 5, Measured: NsOp}, &Bench{N: 100, NsOp: 50, Measured: NsOp}},
+	}
+	measure := func(c BenchCmp) (Delta, bool) { return c.DeltaNsOp(), c.Measured(NsOp) }
+	total := func(c BenchCmp) float64 { return float64(c.Before.N) * c.Before.NsOp }
+
+	// The old runs took 10µs and 30µs, so the second, which halved,
+	// weighs three times the first, which doubled:
+	// exp((1·log 2 + 3·log 0.5) / 4) = 2^(-1/2).
+	mean, n := WeightedGeoMeanFunc(c, measure, total)
+	if want := 1 / math.Sqrt2; math.Abs(mean-want) > 1e-9 || n != 2 {
+		t.Errorf("WeightedGeoMeanFunc: want %f of 2 have %f of %d", want, mean, n)
+	}
+
+	same := func(BenchCmp) float64 { return 1 }
+	plain, _ := GeoMeanFunc(c, measure)
+	if mean, _ := WeightedGeoMeanFunc(c, measure, same); math.Abs(mean-plain) > 1e-9 {
+		t.Errorf("WeightedGeoMeanFunc with equal weights: want %f have %f", plain, mean)
+	}
+	if mean, n := WeightedGeoMeanFunc(nil, measure, total); mean != 1 || n != 0 {
+		t.Errorf("WeightedGeoMeanFunc of none: want 1 of 0 have %f of %d", mean, n)
+	}
+}
+
+func TestSplitName(t *testing.T) {
+	cases := []struct {
+		name  string
+		base  string
+		procs int
+	}{
+		{"BenchmarkFoo", "BenchmarkFoo", 1},
+		{"BenchmarkFoo-8", "BenchmarkFoo", 8},
+		{"BenchmarkFoo-16", "BenchmarkFoo", 16},
+		{"BenchmarkFoo-Bar", "BenchmarkFoo-Bar", 1},
+		{"BenchmarkFoo-Bar-4", "BenchmarkFoo-Bar", 4},
+		{"BenchmarkFoo/size-64-2", "BenchmarkFoo/size-64", 2},
+		{"BenchmarkFoo-", "BenchmarkFoo-", 1},
+		{"BenchmarkFoo-0", "BenchmarkFoo-0", 1},
+		{"BenchmarkFoo-+4", "BenchmarkFoo-+4", 1},
+	}
+	for _, tt := range cases {
+		base, procs := SplitName(tt.name)
+		if base != tt.base || procs != tt.procs {
+			t.Errorf("SplitName(%q): want %q, %d have %q, %d", tt.name, tt.base, tt.procs, base, procs)
+		}
+	}
+}
+
+func TestBenchCmpSorting(t *testing.T) {
+	c := []BenchCmp{
+		{&Bench{Name: "BenchmarkMuchFaster", NsOp: 10, ord: 3}, &Bench{Name: "BenchmarkMuchFaster", NsOp: 1}},
+		{&Bench{Name: "BenchmarkSameB", NsOp: 5, ord: 1}, &Bench{Name: "BenchmarkSameB", NsOp: 5}},
+		{&Bench{Name: "BenchmarkSameA", NsOp: 5, ord: 2}, &Bench{Name: "BenchmarkSameA", NsOp: 5}},
+		{&Bench{Name: "BenchmarkSlower", NsOp: 10, ord: 0}, &Bench{Name: "BenchmarkSlower", NsOp: 11}},
+	}
+
+	// Test just one magnitude-based sort order; they are symmetric.
+	sort.Sort(ByDeltaNsOp(c))
+	want := []string{"BenchmarkMuchFaster", "BenchmarkSlower", "BenchmarkSameA", "BenchmarkSameB"}
+	have := []string{c[0].Name(), c[1].Name(), c[2].Name(), c[3].Name()}
+	if !reflect.DeepEqual(want, have) {
+		t.Errorf("ByDeltaNsOp incorrect sorting: want %v have %v", want, have)
+	}
+
+	sort.Sort(BySignedDeltaNsOp(c))
+	want = []string{"BenchmarkSlower", "BenchmarkSameA", "BenchmarkSameB", "BenchmarkMuchFaster"}
+	have = []string{c[0].Name(), c[1].Name(), c[2].Name(), c[3].Name()}
+	if !reflect.DeepEqual(want, have) {
+		t.Errorf("BySignedDeltaNsOp incorrect sorting: want %v have %v", want, have)
+	}
+
+	// Tie orders only the benchmarks with the same change.
+	byOrd := func(i, j BenchCmp) bool { return i.Before.ord < j.Before.ord }
+	sort.Sort(ByDelta{Cmps: c, Delta: BenchCmp.DeltaNsOp, Tie: byOrd})
+	want = []string{"BenchmarkMuchFaster", "BenchmarkSlower", "BenchmarkSameB", "BenchmarkSameA"}
+	have = []string{c[0].Name(), c[1].Name(), c[2].Name(), c[3].Name()}
+	if !reflect.DeepEqual(want, have) {
+		t.Errorf("ByDelta with Tie incorrect sorting: want %v have %v", want, have)
+	}
+	sort.Sort(BySignedDelta{Cmps: c, Delta: BenchCmp.DeltaNsOp, Tie: byOrd})
+	want = []string{"BenchmarkSlower", "BenchmarkSameB", "BenchmarkSameA", "BenchmarkMuchFaster"}
+	have = []string{c[0].Name(), c[1].Name(), c[2].Name(), c[3].Name()}
+	if !reflect.DeepEqual(want, have) {
+		t.Errorf("BySignedDelta with Tie incorrect sorting: want %v have %v", want, have)
+	}
+
+	sort.Sort(ByParseOrder(c))
+	want = []string{"BenchmarkSlower", "BenchmarkSameB", "BenchmarkSameA", "BenchmarkMuchFaster"}
+	have = []string{c[0].Name(), c[1].Name(), c[2].Name(), c[3].Name()}
+	if !reflect.DeepEqual(want, have) {
+		t.Errorf("ByParseOrder incorrect sorting: want %v have %v", want, have)
+	}
+
+	sort.Sort(ByName(c))
+	want = []string{"BenchmarkMuchFaster", "BenchmarkSameA", "BenchmarkSameB", "BenchmarkSlower"}
+	have = []string{c[0].Name(), c[1].Name(), c[2].Name(), c[3].Name()}
+	if !reflect.DeepEqual(want, have) {
+		t.Errorf("ByName incorrect sorting: want %v have %v", want, have)
+	}
+
+	// Equal names keep their parse order.
+	d := []BenchCmp{
+		{&Bench{Name: "BenchmarkA", NsOp: 1, ord: 2}, &Bench{Name: "BenchmarkA", NsOp: 1}},
+		{&Bench{Name: "BenchmarkB", NsOp: 1, ord: 0}, &Bench{Name: "BenchmarkB", NsOp: 1}},
+		{&Bench{Name: "BenchmarkA", NsOp: 2, ord: 1}, &Bench{Name: "BenchmarkA", NsOp: 2}},
+	}
+	sort.Sort(ByName(d))
+	if d[0].Before.ord != 1 || d[1].Before.ord != 2 || d[2].Name() != "BenchmarkB" {
+		t.Errorf("ByName did not break ties by parse order: have %v %v %v", d[0].Before, d[1].Before, d[2].Before)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchcmp
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FormatNs formats a time in nanoseconds with the precision that
+// testing.B uses for its magnitude: two decimal places below 10ns,
+// one below 100ns, and none above.
+func FormatNs(ns float64) string {
+	prec := 0
+	switch {
+	case ns < 10:
+		prec = 2
+	case ns < 100:
+		prec = 1
+	}
+	return strconv.FormatFloat(ns, 'f', prec, 64)
+}
+
+// flagOf returns the Measured flag of the measurement with the given
+// unit, or 0 if it is an extra measurement.
+func flagOf(unit string) int {
+	for _, u := range units {
+		if u.unit == unit {
+			return u.flag
+		}
+	}
+	return 0
+}
+
+// Format returns the measurement of b with the given unit, such as
+// ns/op or the unit of an extra measurement, formatted as the benchcmp
+// command shows it by default: ns/op by FormatNs, MB/s to two decimal
+// places, bytes and allocations as whole numbers, and extra
+// measurements in full. It returns "" if b did not record it.
+func (b *Bench) Format(unit string) string {
+	flag := flagOf(unit)
+	if flag == 0 {
+		v, ok := b.Extra[unit]
+		if !ok {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	if b.Measured&flag == 0 {
+		return ""
+	}
+	switch flag {
+	case NsOp:
+		return FormatNs(b.NsOp)
+	case MbS:
+		return strconv.FormatFloat(b.MbS, 'f', 2, 64)
+	case BOp:
+		return strconv.FormatUint(b.BOp, 10)
+	}
+	return strconv.FormatUint(b.AllocsOp, 10)
+}
+
+// Header returns the tab-separated column headers of the table of
+// metric, a unit as accepted by Bench.Format, with a row for each
+// benchmark as written by FormatRow.
+func Header(metric string) string {
+	label, change := metric, "delta"
+	switch metric {
+	case "allocs/op":
+		label = "allocs"
+	case "B/op":
+		label = "bytes"
+	case "MB/s":
+		change = "speedup"
+	}
+	return strings.Join([]string{"benchmark", "old " + label, "new " + label, change}, "\t")
+}
+
+// FormatRow returns the tab-separated row of c in the table of metric
+// headed by Header: its name, its old and new values as formatted by
+// Bench.Format, and the change, as a Delta.Multiple for MB/s and a
+// Delta.Percent otherwise. These are the rows of the benchcmp
+// command's text output, without the alignment or the columns added
+// by its flags.
+func (c BenchCmp) FormatRow(metric string) string {
+	var d Delta
+	change := Delta.Percent
+	switch flag := flagOf(metric); flag {
+	case 0:
+		d = c.DeltaExtra(metric)
+	case MbS:
+		d, change = c.Delta(flag), Delta.Multiple
+	default:
+		d = c.Delta(flag)
+	}
+	return strings.Join([]string{c.Name(), c.Before.Format(metric), c.After.Format(metric), change(d)}, "\t")
+}
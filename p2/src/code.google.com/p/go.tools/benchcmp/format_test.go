@@ -0,0 +1,51 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchcmp
+
+import "testing"
+
+func TestFormatNs(t *testing.T) {
+	for _, tt := range []struct {
+		ns   float64
+		want string
+	}{
+		{0.4567, "0.46"},
+		{9.999, "10.00"},
+		{19.6, "19.6"},
+		{517, "517"},
+		{1234567.8, "1234568"},
+	} {
+		if have := FormatNs(tt.ns); have != tt.want {
+			t.Errorf("FormatNs(%v): want %q have %q", tt.ns, tt.want, have)
+		}
+	}
+}
+
+func TestFormatRow(t *testing.T) {
+	m := NsOp | MbS | BOp | AllocsOp
+	c := BenchCmp{
+		Before: &Bench{Name: "BenchmarkEncrypt", NsOp: 19.6, MbS: 817.77, BOp: 3, AllocsOp: 5, Measured: m, Extra: map[string]float64{"items/op": 4}},
+		After:  &Bench{Name: "BenchmarkEncrypt", NsOp: 17.6, MbS: 917.77, BOp: 3, AllocsOp: 4, Measured: m, Extra: map[string]float64{"items/op": 4.5}},
+	}
+	for _, tt := range []struct {
+		metric, header, row string
+	}{
+		{"ns/op", "benchmark\told ns/op\tnew ns/op\tdelta", "BenchmarkEncrypt\t19.6\t17.6\t-10.20%"},
+		{"MB/s", "benchmark\told MB/s\tnew MB/s\tspeedup", "BenchmarkEncrypt\t817.77\t917.77\t1.12x"},
+		{"allocs/op", "benchmark\told allocs\tnew allocs\tdelta", "BenchmarkEncrypt\t5\t4\t-20.00%"},
+		{"B/op", "benchmark\told bytes\tnew bytes\tdelta", "BenchmarkEncrypt\t3\t3\t+0.00%"},
+		{"items/op", "benchmark\told items/op\tnew items/op\tdelta", "BenchmarkEncrypt\t4\t4.5\t+12.50%"},
+	} {
+		if have := Header(tt.metric); have != tt.header {
+			t.Errorf("Header(%q): want %q have %q", tt.metric, tt.header, have)
+		}
+		if have := c.FormatRow(tt.metric); have != tt.row {
+			t.Errorf("FormatRow(%q): want %q have %q", tt.metric, tt.row, have)
+		}
+	}
+	if have := c.Before.Format("p99_ns/op"); have != "" {
+		t.Errorf("Format of an unrecorded measurement: want \"\" have %q", have)
+	}
+}
@@ -0,0 +1,579 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchcmp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flags used by Bench.Measured to indicate
+// which measurements a Bench contains.
+const (
+	NsOp = 1 << iota
+	MbS
+	BOp
+	AllocsOp
+)
+
+// Bench is one run of a single benchmark.
+type Bench struct {
+	Name     string  // benchmark name
+	N        int     // number of iterations
+	NsOp     float64 // nanoseconds per iteration
+	MbS      float64 // MB processed per second
+	BOp      uint64  // bytes allocated per iteration
+	AllocsOp uint64  // allocs per iteration
+	Measured int     // which measurements were recorded, even as 0
+	ord      int     // ordinal position within a benchmark run, used for sorting
+
+	// Extra holds any other measurements, such as those
+	// reported by testing.B.ReportMetric, keyed by unit.
+	Extra map[string]float64
+
+	// Samples holds the individual values of each measurement,
+	// keyed by unit, when the Bench summarizes repeated runs
+	// of a benchmark. See MergeSamples.
+	Samples map[string][]float64
+}
+
+// units holds the unit reported by testing.B for each Measured flag.
+var units = []struct {
+	flag int
+	unit string
+}{
+	{NsOp, "ns/op"},
+	{MbS, "MB/s"},
+	{BOp, "B/op"},
+	{AllocsOp, "allocs/op"},
+}
+
+// Unit returns the unit reported by testing.B for the
+// measurement indicated by flag.
+func Unit(flag int) string {
+	for _, u := range units {
+		if u.flag == flag {
+			return u.unit
+		}
+	}
+	panic(fmt.Sprintf("benchcmp: unknown measurement %d", flag))
+}
+
+// ParseLine extracts a Bench from a single line of testing.B output.
+func ParseLine(line string) (*Bench, error) {
+	fields := strings.Fields(line)
+
+	// Two required, positional fields: Name and iterations.
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("two fields required, have %d", len(fields))
+	}
+	if !strings.HasPrefix(fields[0], "Benchmark") {
+		return nil, fmt.Errorf(`first field does not start with "Benchmark`)
+	}
+	n, err := strconv.Atoi(ungroup(fields[1]))
+	if err != nil {
+		return nil, err
+	}
+	b := &Bench{Name: fields[0], N: n}
+
+	// Parse any remaining pairs of fields; we've parsed one pair already.
+	for i := 1; i < len(fields)/2; i++ {
+		b.parseMeasurement(fields[i*2], fields[i*2+1])
+	}
+	return b, nil
+}
+
+func (b *Bench) parseMeasurement(quant string, unit string) {
+	switch unit {
+	case "ns/op":
+		if f, err := parseFloat(quant); err == nil {
+			b.NsOp = f
+			b.Measured |= NsOp
+		}
+	case "MB/s":
+		if f, err := parseFloat(quant); err == nil {
+			b.MbS = f
+			b.Measured |= MbS
+		}
+	case "B/op":
+		if i, err := parseCount(quant); err == nil {
+			b.BOp = i
+			b.Measured |= BOp
+		}
+	case "allocs/op":
+		if i, err := parseCount(quant); err == nil {
+			b.AllocsOp = i
+			b.Measured |= AllocsOp
+		}
+	default:
+		if f, err := parseFloat(quant); err == nil {
+			if b.Extra == nil {
+				b.Extra = make(map[string]float64)
+			}
+			b.Extra[unit] = f
+		}
+	}
+}
+
+// parseFloat parses a measurement, which may be in scientific notation,
+// as in 1.23e+04, or have its thousands grouped by commas, as in 1,234.5.
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(ungroup(s), 64)
+}
+
+// parseCount parses a measurement that counts bytes or allocations,
+// as parseFloat does, but requires a whole, non-negative number.
+func parseCount(s string) (uint64, error) {
+	s = ungroup(s)
+	if i, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if f < 0 || f != math.Floor(f) || f >= 1<<64 {
+		return 0, fmt.Errorf("invalid count %q", s)
+	}
+	return uint64(f), nil
+}
+
+// ungroup returns s without the commas grouping the thousands of its
+// integer part. If s has commas that do not separate groups of three
+// digits, such as a decimal comma, it is returned unchanged, and so
+// fails to parse.
+func ungroup(s string) string {
+	if !strings.Contains(s, ",") {
+		return s
+	}
+	i := strings.IndexAny(s, ".eE")
+	if i < 0 {
+		i = len(s)
+	}
+	groups := strings.Split(strings.TrimLeft(s[:i], "+-"), ",")
+	if len(groups[0]) == 0 || len(groups[0]) > 3 || strings.Contains(s[i:], ",") {
+		return s
+	}
+	for _, g := range groups[1:] {
+		if len(g) != 3 {
+			return s
+		}
+	}
+	return strings.Replace(s, ",", "", -1)
+}
+
+// String returns b in the format of testing.B output.
+func (b *Bench) String() string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "%s %d", b.Name, b.N)
+	if b.Measured&NsOp != 0 {
+		fmt.Fprintf(buf, " %.2f ns/op", b.NsOp)
+	}
+	if b.Measured&MbS != 0 {
+		fmt.Fprintf(buf, " %.2f MB/s", b.MbS)
+	}
+	if b.Measured&BOp != 0 {
+		fmt.Fprintf(buf, " %d B/op", b.BOp)
+	}
+	if b.Measured&AllocsOp != 0 {
+		fmt.Fprintf(buf, " %d allocs/op", b.AllocsOp)
+	}
+	for _, unit := range b.extraUnits() {
+		fmt.Fprintf(buf, " %g %s", b.Extra[unit], unit)
+	}
+	return buf.String()
+}
+
+// extraUnits returns the units of b.Extra in sorted order.
+func (b *Bench) extraUnits() []string {
+	var u []string
+	for unit := range b.Extra {
+		u = append(u, unit)
+	}
+	sort.Strings(u)
+	return u
+}
+
+// BenchSet is a collection of benchmarks from one
+// testing.B run, keyed by name to faciliate comparison.
+type BenchSet map[string][]*Bench
+
+// Add adds b to bb as the benchmark at position ord in parse order,
+// counting from 0. A parser adding its benchmarks one by one numbers
+// them as it goes, so that they keep the order in which they were read.
+func (bb BenchSet) Add(b *Bench, ord int) {
+	b.ord = ord
+	bb[b.Name] = append(bb[b.Name], b)
+}
+
+// ParseBenchSet extracts a BenchSet from testing.B output. It
+// preserves the order of benchmarks that have identical names.
+// Malformed benchmark lines are skipped; see ParseLog.
+func ParseBenchSet(r io.Reader) (BenchSet, error) {
+	log, err := ParseLog(r)
+	if err != nil {
+		return nil, err
+	}
+	return log.Benchmarks, nil
+}
+
+// A Malformed is a line of testing.B output that appears to report
+// a benchmark but could not be parsed, as when a run is interrupted
+// while writing its results.
+type Malformed struct {
+	Line int    // line number, starting at 1
+	Text string // the line as read
+}
+
+func (m Malformed) String() string {
+	return fmt.Sprintf("line %d: %s", m.Line, m.Text)
+}
+
+// A Log is the parsed output of a go test run of benchmarks.
+type Log struct {
+	Benchmarks BenchSet    // as from ParseBenchSet
+	Malformed  []Malformed // malformed benchmark lines, in order
+
+	// Negative holds the benchmark lines, in order, that report a
+	// negative ns/op or MB/s, which no benchmark can measure, as in a
+	// corrupt log. They are not in Benchmarks, nor in Malformed.
+	// Custom metrics may be negative.
+	Negative []Malformed
+
+	// Skipped holds the names of the benchmarks that were skipped,
+	// as by testing.B.Skip, or that ran for zero iterations, in the
+	// order first seen. They are not in Benchmarks.
+	Skipped []string
+
+	// Duplicates holds the names of the benchmarks that ran again after
+	// other benchmarks, in the order first seen. Go test repeats each
+	// benchmark consecutively, so a benchmark that reappears later
+	// suggests that the output of separate runs was appended to one
+	// file, or that two packages have benchmarks of the same name.
+	// Their runs are in Benchmarks, and so are merged by MergeSamples
+	// as if they were repeated runs.
+	Duplicates []string
+
+	// The configuration reported by go test in lines like "goos: linux",
+	// or "" if not reported. If the log covers several packages,
+	// Pkg is the first.
+	GOOS   string
+	GOARCH string
+	Pkg    string
+}
+
+// MergeLogs returns a Log holding the benchmarks of all of logs, as when
+// the output of one run was split across several files, one for each
+// package. The benchmarks keep the order of logs, and of each log, so a
+// benchmark in more than one log has the runs of each, which are merged
+// by MergeSamples as if they were repeated runs. Malformed, Negative,
+// Skipped, and Duplicates collect those of each log, and each
+// configuration value is the first that a log reports. The logs are
+// not modified.
+func MergeLogs(logs ...*Log) *Log {
+	merged := &Log{Benchmarks: make(BenchSet)}
+	ord := 0
+	for _, log := range logs {
+		next := ord
+		for _, runs := range log.Benchmarks {
+			for _, b := range runs {
+				c := *b
+				merged.Benchmarks.Add(&c, ord+b.ord)
+				if c.ord >= next {
+					next = c.ord + 1
+				}
+			}
+		}
+		ord = next
+		merged.Malformed = append(merged.Malformed, log.Malformed...)
+		merged.Negative = append(merged.Negative, log.Negative...)
+		for _, name := range log.Skipped {
+			merged.Skipped = addName(merged.Skipped, name)
+		}
+		for _, name := range log.Duplicates {
+			merged.Duplicates = addName(merged.Duplicates, name)
+		}
+		if merged.GOOS == "" {
+			merged.GOOS = log.GOOS
+		}
+		if merged.GOARCH == "" {
+			merged.GOARCH = log.GOARCH
+		}
+		if merged.Pkg == "" {
+			merged.Pkg = log.Pkg
+		}
+	}
+	return merged
+}
+
+// DefaultMaxLineSize is the longest line that ParseLog accepts.
+const DefaultMaxLineSize = 1 << 20
+
+// ParseLog parses testing.B output, such as that written by go test.
+// Lines that do not begin with a benchmark name or a known configuration
+// key, such as PASS, are ignored, and are not malformed. ParseLog reads
+// r a line at a time, and fails on lines longer than DefaultMaxLineSize.
+func ParseLog(r io.Reader) (*Log, error) {
+	return ParseLogSize(r, DefaultMaxLineSize)
+}
+
+// ParseLogSize is like ParseLog, but fails on lines longer than
+// maxLine bytes instead.
+func ParseLogSize(r io.Reader, maxLine int) (*Log, error) {
+	log := &Log{Benchmarks: make(BenchSet)}
+	lr := &lineReader{r: bufio.NewReader(r), max: maxLine}
+	ord := 0
+	var last string // name of the last benchmark parsed
+	for lr.scan() {
+		text := string(lr.buf)
+		if log.parseConfig(text) {
+			continue
+		}
+		if name, ok := skipLine(text); ok {
+			log.Skipped = addName(log.Skipped, name)
+			continue
+		}
+		b, err := ParseLine(text)
+		if malformed(text, b, err) {
+			log.Malformed = append(log.Malformed, Malformed{lr.line, text})
+			continue
+		}
+		if err == nil && b.negative() {
+			log.Negative = append(log.Negative, Malformed{lr.line, text})
+			continue
+		}
+		if err == nil && b.N == 0 {
+			log.Skipped = addName(log.Skipped, b.Name)
+			continue
+		}
+		if err == nil {
+			if _, ok := log.Benchmarks[b.Name]; ok && b.Name != last {
+				log.Duplicates = addName(log.Duplicates, b.Name)
+			}
+			last = b.Name
+			log.Benchmarks.Add(b, ord)
+			ord++
+		}
+	}
+
+	if lr.err != nil {
+		return nil, lr.err
+	}
+
+	return log, nil
+}
+
+// A lineReader reads lines of at most max bytes, without their line
+// endings, into a buffer that it reuses for each line. Lines may end
+// in "\n" or, as in logs captured on Windows, "\r\n".
+type lineReader struct {
+	r    *bufio.Reader
+	max  int
+	buf  []byte // the current line
+	line int    // the number of the current line, starting at 1
+	err  error  // the first error other than io.EOF
+}
+
+// scan reads the next line into lr.buf, and reports whether it did so.
+// At the end of the input or on error, it returns false.
+func (lr *lineReader) scan() bool {
+	lr.buf = lr.buf[:0]
+	for {
+		chunk, more, err := lr.r.ReadLine()
+		if err != nil {
+			if err != io.EOF {
+				lr.err = err
+				return false
+			}
+			if len(lr.buf) == 0 {
+				return false
+			}
+			more = false
+		}
+		if len(lr.buf)+len(chunk) > lr.max {
+			lr.err = fmt.Errorf("line %d: longer than %d bytes", lr.line+1, lr.max)
+			return false
+		}
+		lr.buf = append(lr.buf, chunk...)
+		if !more {
+			// ReadLine drops the "\r" of "\r\n", but not
+			// of a last line that lacks the "\n".
+			lr.buf = bytes.TrimSuffix(lr.buf, []byte("\r"))
+			lr.line++
+			return true
+		}
+	}
+}
+
+// skipLine reports whether line is the report of a skipped benchmark
+// written by testing.B, as in "--- SKIP: BenchmarkFoo", and if so
+// returns the benchmark name.
+func skipLine(line string) (name string, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || fields[0] != "---" || fields[1] != "SKIP:" || !strings.HasPrefix(fields[2], "Benchmark") {
+		return "", false
+	}
+	return fields[2], true
+}
+
+// addName returns names with name appended, unless names already
+// holds it.
+func addName(names []string, name string) []string {
+	for _, n := range names {
+		if n == name {
+			return names
+		}
+	}
+	return append(names, name)
+}
+
+// parseConfig records the configuration reported by line, if any,
+// and reports whether it did so.
+func (log *Log) parseConfig(line string) bool {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return false
+	}
+	key, val := line[:i], strings.TrimSpace(line[i+1:])
+	var field *string
+	switch key {
+	case "goos":
+		field = &log.GOOS
+	case "goarch":
+		field = &log.GOARCH
+	case "pkg":
+		field = &log.Pkg
+	default:
+		return false
+	}
+	if *field == "" {
+		*field = val
+	}
+	return true
+}
+
+// negative reports whether b recorded a negative ns/op or MB/s.
+func (b *Bench) negative() bool {
+	return b.Measured&NsOp != 0 && b.NsOp < 0 || b.Measured&MbS != 0 && b.MbS < 0
+}
+
+// malformed reports whether line, which ParseLine parsed as b or failed
+// to parse with err, is a benchmark result that is missing or has
+// garbled measurements. A benchmark name alone on a line is not
+// malformed: testing.B prints one when a benchmark logs output.
+func malformed(line string, b *Bench, err error) bool {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "Benchmark") {
+		return false
+	}
+	if err != nil || len(fields)%2 != 0 {
+		return true
+	}
+	// Every pair of fields after the iterations must be a measurement.
+	n := len(b.Extra)
+	for _, u := range units {
+		if b.Measured&u.flag != 0 {
+			n++
+		}
+	}
+	return n == 0 || n != len(fields)/2-1
+}
+
+// MergeSamples returns a BenchSet with a single Bench for each name in bb,
+// summarizing all of its runs, such as those produced by go test -count.
+// Each measurement of the summary is the mean of the runs that recorded
+// it, and the individual values are kept in Samples. Only measurements
+// recorded by every run are kept. Benchmarks that ran once are unchanged.
+func MergeSamples(bb BenchSet) BenchSet {
+	return MergeSamplesFunc(bb, Mean)
+}
+
+// MergeSamplesFunc is like MergeSamples, but summarizes each measurement
+// of the runs of a benchmark by calling summarize on their values,
+// as with Median, instead of taking their mean.
+func MergeSamplesFunc(bb BenchSet, summarize func([]float64) float64) BenchSet {
+	merged := make(BenchSet, len(bb))
+	for name, runs := range bb {
+		if len(runs) == 1 {
+			merged[name] = runs
+			continue
+		}
+		b := &Bench{Name: name, ord: runs[0].ord, Measured: runs[0].Measured}
+		var n int
+		for _, run := range runs {
+			b.Measured &= run.Measured
+			n += run.N
+		}
+		b.N = n / len(runs)
+		b.Samples = make(map[string][]float64)
+		for _, u := range units {
+			if b.Measured&u.flag == 0 {
+				continue
+			}
+			x := make([]float64, len(runs))
+			for i, run := range runs {
+				x[i] = run.value(u.flag)
+			}
+			b.Samples[u.unit] = x
+			b.setValue(u.flag, summarize(x))
+		}
+	Extra:
+		for _, unit := range runs[0].extraUnits() {
+			x := make([]float64, len(runs))
+			for i, run := range runs {
+				v, ok := run.Extra[unit]
+				if !ok {
+					continue Extra
+				}
+				x[i] = v
+			}
+			if b.Extra == nil {
+				b.Extra = make(map[string]float64)
+			}
+			b.Samples[unit] = x
+			b.Extra[unit] = summarize(x)
+		}
+		merged[name] = []*Bench{b}
+	}
+	return merged
+}
+
+// value returns the measurement indicated by flag.
+func (b *Bench) value(flag int) float64 {
+	switch flag {
+	case NsOp:
+		return b.NsOp
+	case MbS:
+		return b.MbS
+	case BOp:
+		return float64(b.BOp)
+	case AllocsOp:
+		return float64(b.AllocsOp)
+	}
+	panic(fmt.Sprintf("benchcmp: unknown measurement %d", flag))
+}
+
+// setValue sets the measurement indicated by flag, rounding
+// to the nearest integer for integral measurements.
+func (b *Bench) setValue(flag int, v float64) {
+	switch flag {
+	case NsOp:
+		b.NsOp = v
+	case MbS:
+		b.MbS = v
+	case BOp:
+		b.BOp = uint64(v + 0.5)
+	case AllocsOp:
+		b.AllocsOp = uint64(v + 0.5)
+	default:
+		panic(fmt.Sprintf("benchcmp: unknown measurement %d", flag))
+	}
+}
@@ -0,0 +1,522 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchcmp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLine(t *testing.T) {
+	cases := []struct {
+		line string
+		want *Bench
+		err  bool // expect an error
+	}{
+		{
+			line: "BenchmarkEncrypt	100000000	        19.6 ns/op",
+			want: &Bench{
+				Name: "BenchmarkEncrypt",
+				N:    100000000, NsOp: 19.6,
+				Measured: NsOp,
+			},
+		},
+		{
+			line: "BenchmarkEncrypt	100000000	        19.6 ns/op	 817.77 MB/s",
+			want: &Bench{
+				Name: "BenchmarkEncrypt",
+				N:    100000000, NsOp: 19.6, MbS: 817.77,
+				Measured: NsOp | MbS,
+			},
+		},
+		{
+			line: "BenchmarkEncrypt	100000000	        19.6 ns/op	 817.77",
+			want: &Bench{
+				Name: "BenchmarkEncrypt",
+				N:    100000000, NsOp: 19.6,
+				Measured: NsOp,
+			},
+		},
+		{
+			line: "BenchmarkEncrypt	100000000	        19.6 ns/op	 817.77 MB/s	       5 allocs/op",
+			want: &Bench{
+				Name: "BenchmarkEncrypt",
+				N:    100000000, NsOp: 19.6, MbS: 817.77, AllocsOp: 5,
+				Measured: NsOp | MbS | AllocsOp,
+			},
+		},
+		{
+			line: "BenchmarkEncrypt	100000000	        19.6 ns/op	 817.77 MB/s	       3 B/op	       5 allocs/op",
+			want: &Bench{
+				Name: "BenchmarkEncrypt",
+				N:    100000000, NsOp: 19.6, MbS: 817.77, BOp: 3, AllocsOp: 5,
+				Measured: NsOp | MbS | BOp | AllocsOp,
+			},
+		},
+		// error handling cases
+		{
+			line: "BenchPress	100	        19.6 ns/op", // non-benchmark
+			err: true,
+		},
+		{
+			line: "BenchmarkEncrypt	lots	        19.6 ns/op", // non-int iterations
+			err: true,
+		},
+		{
+			line: "BenchmarkBridge	100000000	        19.6 smoots", // unknown unit
+			want: &Bench{
+				Name:  "BenchmarkBridge",
+				N:     100000000,
+				Extra: map[string]float64{"smoots": 19.6},
+			},
+		},
+		{
+			line: "BenchmarkRequests	100	        12.3 ns/op	 5.0 items/op	 2.5e3 req/s",
+			want: &Bench{
+				Name: "BenchmarkRequests",
+				N:    100, NsOp: 12.3,
+				Measured: NsOp,
+				Extra:    map[string]float64{"items/op": 5, "req/s": 2500},
+			},
+		},
+		{
+			line: "BenchmarkHarness	1,000	        1,234.5 ns/op	 1.2e3 B/op	       1,024 allocs/op	 1,234,567 req/s",
+			want: &Bench{
+				Name: "BenchmarkHarness",
+				N:    1000, NsOp: 1234.5, BOp: 1200, AllocsOp: 1024,
+				Measured: NsOp | BOp | AllocsOp,
+				Extra:    map[string]float64{"req/s": 1234567},
+			},
+		},
+		{
+			line: "BenchmarkHarness	100	        1.23e+04 ns/op	 2.5e0 B/op	 3,4 allocs/op	 12,34.5 MB/s",
+			want: &Bench{
+				Name: "BenchmarkHarness",
+				N:    100, NsOp: 12300,
+				Measured: NsOp,
+			},
+		},
+		{
+			line: "PASS",
+			err:  true,
+		},
+	}
+
+	for _, tt := range cases {
+		have, err := ParseLine(tt.line)
+		if tt.err && err == nil {
+			t.Errorf("parsing line %q should have failed", tt.line)
+			continue
+		}
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("parsed line %q incorrectly, want %v have %v", tt.line, tt.want, have)
+		}
+	}
+}
+
+func TestUngroup(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{"1234.5", "1234.5"},
+		{"1,234.5", "1234.5"},
+		{"-12,345,678", "-12345678"},
+		{"1,234e3", "1234e3"},
+		{"1,5", "1,5"},
+		{"1234,567", "1234,567"},
+		{",123", ",123"},
+		{"1.234,5", "1.234,5"},
+	} {
+		if have := ungroup(tt.in); have != tt.want {
+			t.Errorf("ungroup(%q): want %q have %q", tt.in, tt.want, have)
+		}
+	}
+}
+
+func TestParseBenchSet(t *testing.T) {
+	// Test two things:
+	// 1. The noise that can accompany testing.B output gets ignored.
+	// 2. Benchmarks with the same name have their order preserved.
+	in := `
+		?   	crypto	[no test files]
+		PASS
+				pem_decrypt_test.go:17: test 4. %!s(x509.PEMCipher=5)
+			... [output truncated]
+
+		BenchmarkEncrypt	100000000	        19.6 ns/op
+		BenchmarkEncrypt	 5000000	       517 ns/op
+		=== RUN TestChunk
+		--- PASS: TestChunk (0.00 seconds)
+		--- SKIP: TestLinuxSendfile (0.00 seconds)
+			fs_test.go:716: skipping; linux-only test
+		BenchmarkReadRequestApachebench	 1000000	      2960 ns/op	  27.70 MB/s	     839 B/op	       9 allocs/op
+		BenchmarkClientServerParallel64	   50000	     59192 ns/op	    7028 B/op	      60 allocs/op
+		ok  	net/http	95.783s
+	`
+
+	want := BenchSet{
+		"BenchmarkReadRequestApachebench": []*Bench{
+			{
+				Name: "BenchmarkReadRequestApachebench",
+				N:    1000000, NsOp: 2960, MbS: 27.70, BOp: 839, AllocsOp: 9,
+				Measured: NsOp | MbS | BOp | AllocsOp,
+				ord:      2,
+			},
+		},
+		"BenchmarkClientServerParallel64": []*Bench{
+			{
+				Name: "BenchmarkClientServerParallel64",
+				N:    50000, NsOp: 59192, BOp: 7028, AllocsOp: 60,
+				Measured: NsOp | BOp | AllocsOp,
+				ord:      3,
+			},
+		},
+		"BenchmarkEncrypt": []*Bench{
+			{
+				Name: "BenchmarkEncrypt",
+				N:    100000000, NsOp: 19.6,
+				Measured: NsOp,
+				ord:      0,
+			},
+			{
+				Name: "BenchmarkEncrypt",
+				N:    5000000, NsOp: 517,
+				Measured: NsOp,
+				ord:      1,
+			},
+		},
+	}
+
+	have, err := ParseBenchSet(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected err during ParseBenchSet: %v", err)
+	}
+	if !reflect.DeepEqual(want, have) {
+		t.Errorf("parsed bench set incorrectly, want %v have %v", want, have)
+	}
+}
+
+func TestBenchSetAdd(t *testing.T) {
+	bb := make(BenchSet)
+	bb.Add(&Bench{Name: "BenchmarkB"}, 0)
+	bb.Add(&Bench{Name: "BenchmarkA"}, 1)
+	bb.Add(&Bench{Name: "BenchmarkB"}, 2)
+	if len(bb["BenchmarkA"]) != 1 || len(bb["BenchmarkB"]) != 2 {
+		t.Fatalf("Add: wrong benchmarks %v", bb)
+	}
+	if bb["BenchmarkB"][0].ord != 0 || bb["BenchmarkA"][0].ord != 1 || bb["BenchmarkB"][1].ord != 2 {
+		t.Errorf("Add: benchmarks not in parse order")
+	}
+}
+
+func TestParseLog(t *testing.T) {
+	in := `goos: linux
+goarch: amd64
+pkg: crypto/aes
+PASS
+BenchmarkEncrypt	100000000	        19.6 ns/op
+BenchmarkLogs
+BenchmarkTruncated	 5000000	       517
+BenchmarkNoIters	ns/op
+BenchmarkGarbled	 5000000	       5x17 ns/op
+BenchmarkEmpty	 5000000
+BenchmarkDecrypt	 5000000	       517 ns/op
+ok  	crypto/aes	1.234s
+pkg: crypto/cipher
+BenchmarkSeal	 5000000	       300 ns/op
+`
+
+	log, err := ParseLog(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	bb := log.Benchmarks
+	if len(bb) != 3 || len(bb["BenchmarkEncrypt"]) != 1 || len(bb["BenchmarkDecrypt"]) != 1 || len(bb["BenchmarkSeal"]) != 1 {
+		t.Errorf("ParseLog parsed wrong benchmarks: %v", bb)
+	}
+	if bb["BenchmarkDecrypt"][0].ord != 1 {
+		t.Errorf("ParseLog: malformed lines counted in parse order")
+	}
+
+	want := []Malformed{
+		{7, "BenchmarkTruncated\t 5000000\t       517"},
+		{8, "BenchmarkNoIters\tns/op"},
+		{9, "BenchmarkGarbled\t 5000000\t       5x17 ns/op"},
+		{10, "BenchmarkEmpty\t 5000000"},
+	}
+	if !reflect.DeepEqual(want, log.Malformed) {
+		t.Errorf("ParseLog malformed lines:\nwant %v\nhave %v", want, log.Malformed)
+	}
+
+	if log.GOOS != "linux" || log.GOARCH != "amd64" || log.Pkg != "crypto/aes" {
+		t.Errorf("ParseLog configuration: want linux/amd64 crypto/aes have %s/%s %s", log.GOOS, log.GOARCH, log.Pkg)
+	}
+}
+
+func TestParseLogSkipped(t *testing.T) {
+	// As written by go test -bench=. -count=2 with b.Skip
+	// in BenchmarkSkip and BenchmarkSub/linux.
+	in := `goos: linux
+BenchmarkRun-8     	 5000000	       300 ns/op
+BenchmarkSkip
+--- SKIP: BenchmarkSkip
+    skip_test.go:12: requires cgo
+BenchmarkSkip
+--- SKIP: BenchmarkSkip
+    skip_test.go:12: requires cgo
+BenchmarkSub/linux-8
+    --- SKIP: BenchmarkSub/linux
+    	skip_test.go:20: linux only
+BenchmarkZero-8     	       0	         0 ns/op
+--- SKIP: TestOther (0.00s)
+PASS
+`
+	log, err := ParseLog(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	if len(log.Benchmarks) != 1 || len(log.Benchmarks["BenchmarkRun-8"]) != 1 {
+		t.Errorf("ParseLog parsed wrong benchmarks: %v", log.Benchmarks)
+	}
+	if want := []string{"BenchmarkSkip", "BenchmarkSub/linux", "BenchmarkZero-8"}; !reflect.DeepEqual(want, log.Skipped) {
+		t.Errorf("ParseLog skipped: want %v have %v", want, log.Skipped)
+	}
+	if len(log.Malformed) != 0 {
+		t.Errorf("ParseLog: skipped benchmarks reported as malformed: %v", log.Malformed)
+	}
+}
+
+func TestParseLogNegative(t *testing.T) {
+	in := `BenchmarkEncrypt	100000000	        19.6 ns/op
+BenchmarkCorrupt	 5000000	      -517 ns/op
+BenchmarkSlowRead	 5000000	       517 ns/op	  -3.50 MB/s
+BenchmarkDelta	 5000000	       517 ns/op	        -2 drift/op
+`
+	log, err := ParseLog(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	if len(log.Benchmarks) != 2 || log.Benchmarks["BenchmarkEncrypt"] == nil || log.Benchmarks["BenchmarkDelta"] == nil {
+		t.Errorf("ParseLog parsed wrong benchmarks: %v", log.Benchmarks)
+	}
+	want := []Malformed{
+		{2, "BenchmarkCorrupt\t 5000000\t      -517 ns/op"},
+		{3, "BenchmarkSlowRead\t 5000000\t       517 ns/op\t  -3.50 MB/s"},
+	}
+	if !reflect.DeepEqual(want, log.Negative) {
+		t.Errorf("ParseLog negative lines:\nwant %v\nhave %v", want, log.Negative)
+	}
+	if len(log.Malformed) != 0 {
+		t.Errorf("ParseLog: negative lines reported as malformed: %v", log.Malformed)
+	}
+}
+
+func TestParseZeroAllocs(t *testing.T) {
+	// A benchmark that makes no allocations reports 0 allocs/op with
+	// -benchmem, which is a measurement, unlike an absent column.
+	before := `BenchmarkZero	100	50 ns/op	0 B/op	0 allocs/op
+BenchmarkAbsent	100	50 ns/op
+`
+	after := `BenchmarkZero	100	50 ns/op	48 B/op	3 allocs/op
+BenchmarkAbsent	100	50 ns/op	48 B/op	3 allocs/op
+`
+	old, err := ParseBenchSet(strings.NewReader(before))
+	if err != nil {
+		t.Fatalf("ParseBenchSet failed: %v", err)
+	}
+	new, err := ParseBenchSet(strings.NewReader(after))
+	if err != nil {
+		t.Fatalf("ParseBenchSet failed: %v", err)
+	}
+	cmps, _ := Correlate(old, new)
+	if len(cmps) != 2 {
+		t.Fatalf("Correlate: want 2 comparisons have %d", len(cmps))
+	}
+	for _, cmp := range cmps {
+		switch cmp.Name() {
+		case "BenchmarkZero":
+			if !cmp.Measured(AllocsOp) || !cmp.Measured(BOp) {
+				t.Errorf("%s: 0 allocs/op and 0 B/op should be measured", cmp.Name())
+			}
+			if d := cmp.DeltaAllocsOp(); d.Before != 0 || d.After != 3 || !d.Changed() {
+				t.Errorf("%s: want allocs/op to change from 0 to 3 have %v", cmp.Name(), d)
+			}
+		case "BenchmarkAbsent":
+			if cmp.Measured(AllocsOp) || cmp.Measured(BOp) {
+				t.Errorf("%s: allocs/op and B/op absent from the old run should not be measured", cmp.Name())
+			}
+		default:
+			t.Errorf("Correlate: unexpected benchmark %s", cmp.Name())
+		}
+	}
+}
+
+func TestParseLogDuplicates(t *testing.T) {
+	// The output of go test -count=2, appended to a log twice.
+	run := `goos: linux
+BenchmarkEncrypt-8   	100000000	        19.6 ns/op
+BenchmarkEncrypt-8   	100000000	        19.8 ns/op
+BenchmarkDecrypt-8   	 5000000	       517 ns/op
+BenchmarkDecrypt-8   	 5000000	       519 ns/op
+PASS
+ok  	crypto/aes	4.200s
+`
+	log, err := ParseLog(strings.NewReader(run))
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	if len(log.Duplicates) != 0 {
+		t.Errorf("ParseLog: consecutive runs reported as duplicates: %v", log.Duplicates)
+	}
+
+	log, err = ParseLog(strings.NewReader(run + run))
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	if want := []string{"BenchmarkEncrypt-8", "BenchmarkDecrypt-8"}; !reflect.DeepEqual(want, log.Duplicates) {
+		t.Errorf("ParseLog duplicates: want %v have %v", want, log.Duplicates)
+	}
+	// The runs are kept, and merged as repeated runs.
+	if n := len(log.Benchmarks["BenchmarkEncrypt-8"]); n != 4 {
+		t.Errorf("ParseLog: want 4 runs of BenchmarkEncrypt-8 have %d", n)
+	}
+}
+
+func TestMergeLogs(t *testing.T) {
+	aes, err := ParseLog(strings.NewReader(`goos: linux
+pkg: crypto/aes
+BenchmarkEncrypt	100000000	        19.6 ns/op
+BenchmarkDecrypt	 5000000	       517 ns/op
+`))
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	sha, err := ParseLog(strings.NewReader(`goos: linux
+goarch: amd64
+pkg: crypto/sha1
+BenchmarkHash	1000000	      1200 ns/op
+BenchmarkEncrypt	100000000	        21.0 ns/op
+`))
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+
+	log := MergeLogs(aes, sha)
+	if log.GOOS != "linux" || log.GOARCH != "amd64" || log.Pkg != "crypto/aes" {
+		t.Errorf("MergeLogs: want linux, amd64, crypto/aes have %q, %q, %q", log.GOOS, log.GOARCH, log.Pkg)
+	}
+	if n := len(log.Benchmarks["BenchmarkEncrypt"]); n != 2 {
+		t.Errorf("MergeLogs: want 2 runs of BenchmarkEncrypt have %d", n)
+	}
+	// The runs of the second log follow those of the first.
+	bb := log.Benchmarks
+	have := []int{bb["BenchmarkEncrypt"][0].ord, bb["BenchmarkDecrypt"][0].ord, bb["BenchmarkHash"][0].ord, bb["BenchmarkEncrypt"][1].ord}
+	if want := []int{0, 1, 2, 3}; !reflect.DeepEqual(want, have) {
+		t.Errorf("MergeLogs order: want %v have %v", want, have)
+	}
+	if len(aes.Benchmarks["BenchmarkEncrypt"]) != 1 || len(sha.Benchmarks) != 2 {
+		t.Errorf("MergeLogs modified its arguments")
+	}
+}
+
+func TestParseLogCRLF(t *testing.T) {
+	lf := `goos: windows
+goarch: amd64
+pkg: crypto/aes
+BenchmarkEncrypt	100000000	        19.6 ns/op	     817.77 MB/s
+BenchmarkTruncated	 5000000	       517
+BenchmarkDecrypt	 5000000	       517 ns/op	       8 B/op	       1 allocs/op
+PASS
+BenchmarkLast	 5000000	       300`
+	want, err := ParseLog(strings.NewReader(lf))
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	// As written on Windows, and again with a final line ending.
+	crlf := strings.Replace(lf, "\n", "\r\n", -1)
+	for _, in := range []string{crlf, crlf + "\r\n"} {
+		have, err := ParseLog(strings.NewReader(in))
+		if err != nil {
+			t.Fatalf("ParseLog of CRLF input failed: %v", err)
+		}
+		if !reflect.DeepEqual(want, have) {
+			t.Errorf("ParseLog of CRLF input:\nwant %+v\nhave %+v", want, have)
+		}
+	}
+	// The last line, which is malformed, keeps its text without the "\r"
+	// even if it lacks the "\n".
+	have, err := ParseLog(strings.NewReader(crlf + "\r"))
+	if err != nil {
+		t.Fatalf("ParseLog of CRLF input failed: %v", err)
+	}
+	if !reflect.DeepEqual(want, have) {
+		t.Errorf("ParseLog of CRLF input ending in \\r:\nwant %+v\nhave %+v", want, have)
+	}
+}
+
+func TestParseLogLongLines(t *testing.T) {
+	// A line longer than bufio's buffer, but within the limit.
+	long := "BenchmarkLong" + strings.Repeat("x", 100000) + "\t100\t5 ns/op"
+	in := "BenchmarkEncrypt\t100\t19.6 ns/op\r\n" + long + "\nBenchmarkDecrypt\t100\t517 ns/op"
+	log, err := ParseLog(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	if len(log.Benchmarks) != 3 {
+		t.Errorf("ParseLog: want 3 benchmarks have %d", len(log.Benchmarks))
+	}
+
+	// A corrupt log with a 10MB line.
+	in = "BenchmarkEncrypt\t100\t19.6 ns/op\n" + strings.Repeat("x", 10<<20) + "\n"
+	_, err = ParseLog(strings.NewReader(in))
+	if want := fmt.Sprintf("line 2: longer than %d bytes", DefaultMaxLineSize); err == nil || err.Error() != want {
+		t.Errorf("ParseLog of oversized line: want error %q have %v", want, err)
+	}
+
+	_, err = ParseLogSize(strings.NewReader("PASS\nok\n"+long), 1000)
+	if want := "line 3: longer than 1000 bytes"; err == nil || err.Error() != want {
+		t.Errorf("ParseLogSize: want error %q have %v", want, err)
+	}
+}
+
+func TestMergeSamples(t *testing.T) {
+	bb := BenchSet{
+		"BenchmarkOnce": []*Bench{
+			{Name: "BenchmarkOnce", N: 10, NsOp: 5, Measured: NsOp, ord: 3},
+		},
+		"BenchmarkCount": []*Bench{
+			{Name: "BenchmarkCount", N: 100, NsOp: 10, BOp: 4, MbS: 1, Measured: NsOp | BOp | MbS, ord: 0,
+				Extra: map[string]float64{"items/op": 1, "req/s": 7}},
+			{Name: "BenchmarkCount", N: 200, NsOp: 20, BOp: 5, Measured: NsOp | BOp, ord: 1,
+				Extra: map[string]float64{"items/op": 2}},
+			{Name: "BenchmarkCount", N: 300, NsOp: 60, BOp: 5, Measured: NsOp | BOp, ord: 2,
+				Extra: map[string]float64{"items/op": 6}},
+		},
+	}
+	want := BenchSet{
+		"BenchmarkOnce": bb["BenchmarkOnce"],
+		"BenchmarkCount": []*Bench{
+			{
+				Name: "BenchmarkCount",
+				N:    200, NsOp: 30, BOp: 5,
+				Measured: NsOp | BOp,
+				Extra:    map[string]float64{"items/op": 3},
+				Samples: map[string][]float64{
+					"ns/op":    {10, 20, 60},
+					"B/op":     {4, 5, 5},
+					"items/op": {1, 2, 6},
+				},
+			},
+		},
+	}
+	if have := MergeSamples(bb); !reflect.DeepEqual(want, have) {
+		t.Errorf("MergeSamples incorrect: want %v have %v", want, have)
+	}
+
+	median := MergeSamplesFunc(bb, Median)["BenchmarkCount"][0]
+	if median.NsOp != 20 || median.BOp != 5 || median.Extra["items/op"] != 2 {
+		t.Errorf("MergeSamplesFunc(Median) incorrect: have %v", median)
+	}
+}
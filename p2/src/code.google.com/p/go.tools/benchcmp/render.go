@@ -0,0 +1,22 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchcmp
+
+import "io"
+
+// A Renderer writes a comparison of benchmarks to w in some format.
+type Renderer interface {
+	Render(w io.Writer, cmps []BenchCmp) error
+}
+
+// The RendererFunc type is an adapter to allow the use of ordinary
+// functions as Renderers. If f is a function with the appropriate
+// signature, RendererFunc(f) is a Renderer that calls f.
+type RendererFunc func(w io.Writer, cmps []BenchCmp) error
+
+// Render calls f(w, cmps).
+func (f RendererFunc) Render(w io.Writer, cmps []BenchCmp) error {
+	return f(w, cmps)
+}
@@ -0,0 +1,29 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchcmp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestRendererFunc(t *testing.T) {
+	var r Renderer = RendererFunc(func(w io.Writer, cmps []BenchCmp) error {
+		for _, cmp := range cmps {
+			fmt.Fprintf(w, "%s %s\n", cmp.Name(), cmp.DeltaNsOp().Percent())
+		}
+		return nil
+	})
+	cmps := []BenchCmp{{Before: &Bench{Name: "BenchmarkA", NsOp: 100}, After: &Bench{Name: "BenchmarkA", NsOp: 90}}}
+	buf := new(bytes.Buffer)
+	if err := r.Render(buf, cmps); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if want, have := "BenchmarkA -10.00%\n", buf.String(); want != have {
+		t.Errorf("Render: want %q have %q", want, have)
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchcmp
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+var errTooFewSamples = errors.New("too few samples")
+
+// Mean returns the arithmetic mean of x.
+func Mean(x []float64) float64 {
+	var sum float64
+	for _, v := range x {
+		sum += v
+	}
+	return sum / float64(len(x))
+}
+
+// variance returns the unbiased sample variance of x.
+func variance(x []float64) float64 {
+	m := Mean(x)
+	var sum float64
+	for _, v := range x {
+		sum += (v - m) * (v - m)
+	}
+	return sum / float64(len(x)-1)
+}
+
+// Median returns the median of x, which must not be empty.
+// If x has an even number of samples, Median returns the mean
+// of the two middle ones.
+func Median(x []float64) float64 {
+	s := append([]float64(nil), x...)
+	sort.Float64s(s)
+	n := len(s)
+	if n%2 == 0 {
+		return (s[n/2-1] + s[n/2]) / 2
+	}
+	return s[n/2]
+}
+
+// StdDev returns the sample standard deviation of x,
+// which must have at least two samples.
+func StdDev(x []float64) float64 {
+	return math.Sqrt(variance(x))
+}
+
+// CoV returns the coefficient of variation of x, its standard
+// deviation relative to its mean, or 0 if the mean is 0.
+// x must have at least two samples.
+func CoV(x []float64) float64 {
+	m := Mean(x)
+	if m == 0 {
+		return 0
+	}
+	return StdDev(x) / math.Abs(m)
+}
+
+// RelStdDev returns the relative standard deviation, as a fraction,
+// of the samples that b summarizes for the measurement with the given
+// unit. It reports false if there are fewer than two samples.
+func (b *Bench) RelStdDev(unit string) (float64, bool) {
+	x := b.Samples[unit]
+	if len(x) < 2 {
+		return 0, false
+	}
+	return CoV(x), true
+}
+
+// WelchTTest performs Welch's two-sample t-test on x and y, which need
+// not have equal variances or sizes. It returns the t statistic and
+// the two-tailed p-value for the null hypothesis that x and y have
+// equal means. Each of x and y must have at least two samples.
+func WelchTTest(x, y []float64) (t, p float64, err error) {
+	if len(x) < 2 || len(y) < 2 {
+		return 0, 0, errTooFewSamples
+	}
+	nx, ny := float64(len(x)), float64(len(y))
+	vx, vy := variance(x)/nx, variance(y)/ny
+	diff := Mean(x) - Mean(y)
+	if vx+vy == 0 {
+		// Both samples are constant; only their means can differ.
+		if diff == 0 {
+			return 0, 1, nil
+		}
+		return math.Copysign(math.Inf(1), diff), 0, nil
+	}
+	t = diff / math.Sqrt(vx+vy)
+	// Welch–Satterthwaite approximation of the degrees of freedom.
+	df := (vx + vy) * (vx + vy) / (vx*vx/(nx-1) + vy*vy/(ny-1))
+	p = betaInc(df/(df+t*t), df/2, 0.5)
+	return t, p, nil
+}
+
+// MannWhitneyUTest performs the Mann-Whitney U test on x and y, which
+// makes no assumption about how either is distributed. It returns the
+// U statistic of x and the two-tailed p-value for the null hypothesis
+// that x and y are drawn from the same distribution. The p-value is
+// exact for small samples without ties, and otherwise uses the normal
+// approximation with a correction for ties. Each of x and y must have
+// at least two samples.
+func MannWhitneyUTest(x, y []float64) (u, p float64, err error) {
+	if len(x) < 2 || len(y) < 2 {
+		return 0, 0, errTooFewSamples
+	}
+	nx, ny := float64(len(x)), float64(len(y))
+	rx, ties := rankSum(x, y)
+	u = rx - nx*(nx+1)/2
+	if ties == 0 && len(x)+len(y) <= maxExactU {
+		return u, uExactP(int(u), len(x), len(y)), nil
+	}
+	n := nx + ny
+	// The tie correction reduces the variance of U by the sum of
+	// t³-t over each group of t tied values.
+	v := nx * ny / 12 * (n + 1 - ties/(n*(n-1)))
+	if v == 0 {
+		// Every value is the same.
+		return u, 1, nil
+	}
+	// Continuity correction.
+	z := (math.Abs(u-nx*ny/2) - 0.5) / math.Sqrt(v)
+	if z < 0 {
+		z = 0
+	}
+	return u, math.Erfc(z / math.Sqrt2), nil
+}
+
+// maxExactU is the largest combined sample size for which
+// MannWhitneyUTest computes the exact distribution of U.
+const maxExactU = 50
+
+// rankSum ranks the values of x and y together, giving tied values the
+// mean of the ranks they span, and returns the sum of the ranks of x
+// along with the tie correction term, the sum of t³-t over each group
+// of t tied values.
+func rankSum(x, y []float64) (sum, ties float64) {
+	all := make(byValue, 0, len(x)+len(y))
+	for _, v := range x {
+		all = append(all, sample{v, true})
+	}
+	for _, v := range y {
+		all = append(all, sample{v, false})
+	}
+	sort.Sort(all)
+	for i := 0; i < len(all); {
+		j := i + 1
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		// Samples i through j-1 are tied for ranks i+1 through j.
+		rank := float64(i+j+1) / 2
+		for _, s := range all[i:j] {
+			if s.inX {
+				sum += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+	return sum, ties
+}
+
+// sample is a value being ranked by rankSum, along with
+// which of the two sets it came from.
+type sample struct {
+	v   float64
+	inX bool
+}
+
+type byValue []sample
+
+func (x byValue) Len() int           { return len(x) }
+func (x byValue) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
+func (x byValue) Less(i, j int) bool { return x[i].v < x[j].v }
+
+// uExactP returns the exact two-tailed p-value of the U statistic u of
+// a set of nx values against a set of ny values, none of them tied.
+func uExactP(u, nx, ny int) float64 {
+	// count[j][k] is the number of orderings of i values from the
+	// first set and j from the second in which U is k, for the
+	// current i.
+	max := nx * ny
+	count := make([][]float64, ny+1)
+	for j := range count {
+		count[j] = make([]float64, max+1)
+		count[j][0] = 1
+	}
+	for i := 1; i <= nx; i++ {
+		for j := 1; j <= ny; j++ {
+			// The largest value comes from the first set, adding
+			// j to U, or from the second, adding nothing.
+			for k := max; k >= 0; k-- {
+				var c float64
+				if k >= j {
+					c = count[j][k-j]
+				}
+				count[j][k] = c + count[j-1][k]
+			}
+		}
+	}
+	// The distribution of U is symmetric, so the two-tailed
+	// p-value is twice the smaller tail.
+	if u > max-u {
+		u = max - u
+	}
+	var total, tail float64
+	for k, c := range count[ny] {
+		total += c
+		if k <= u {
+			tail += c
+		}
+	}
+	return math.Min(1, 2*tail/total)
+}
+
+// betaInc returns the regularized incomplete beta function I_x(a, b).
+func betaInc(x, a, b float64) float64 {
+	switch {
+	case x <= 0:
+		return 0
+	case x >= 1:
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	// The continued fraction converges rapidly only for
+	// x < (a+1)/(a+b+2); use the symmetry relation otherwise.
+	if x < (a+1)/(a+b+2) {
+		return front * betaCF(x, a, b) / a
+	}
+	return 1 - front*betaCF(1-x, b, a)/b
+}
+
+// betaCF evaluates the continued fraction for the incomplete beta
+// function using the modified Lentz method.
+func betaCF(x, a, b float64) float64 {
+	const (
+		maxIter = 200
+		epsilon = 3e-14
+		tiny    = 1e-300
+	)
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIter; m++ {
+		m := float64(m)
+		// Even step.
+		num := m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		// Odd step.
+		num = -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
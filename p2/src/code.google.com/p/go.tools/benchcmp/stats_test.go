@@ -0,0 +1,182 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchcmp
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWelchTTest(t *testing.T) {
+	cases := []struct {
+		x, y []float64
+		t, p float64
+	}{
+		// Reference values computed independently from the
+		// Welch–Satterthwaite formulas.
+		{
+			x: []float64{1, 2, 3, 4, 5},
+			y: []float64{2, 4, 6, 8, 10, 12},
+			t: -2.376354, p: 0.049284,
+		},
+		{
+			x: []float64{10.1, 10.3, 9.9, 10.0},
+			y: []float64{10.2, 10.0, 10.1, 10.3},
+			t: -0.700649, p: 0.511639,
+		},
+		{
+			x: []float64{100, 102, 98, 101, 99},
+			y: []float64{110, 111, 109, 112, 108},
+			t: -10, p: 8.488182e-06,
+		},
+		// Constant samples.
+		{x: []float64{5, 5}, y: []float64{5, 5, 5}, t: 0, p: 1},
+		{x: []float64{4, 4}, y: []float64{5, 5}, t: math.Inf(-1), p: 0},
+	}
+	for _, tt := range cases {
+		tv, p, err := WelchTTest(tt.x, tt.y)
+		if err != nil {
+			t.Errorf("WelchTTest(%v, %v) failed: %v", tt.x, tt.y, err)
+			continue
+		}
+		if !approxEqual(tv, tt.t) || !approxEqual(p, tt.p) {
+			t.Errorf("WelchTTest(%v, %v): want t=%g p=%g have t=%g p=%g", tt.x, tt.y, tt.t, tt.p, tv, p)
+		}
+	}
+
+	if _, _, err := WelchTTest([]float64{1}, []float64{1, 2}); err == nil {
+		t.Errorf("WelchTTest with one sample should have failed")
+	}
+}
+
+func TestMannWhitneyUTest(t *testing.T) {
+	cases := []struct {
+		x, y []float64
+		u, p float64
+	}{
+		// Exact p-values, computed by enumerating every ordering.
+		{
+			x: []float64{1, 2, 3, 4, 5},
+			y: []float64{6, 7, 8, 9, 10},
+			u: 0, p: 0.0079365,
+		},
+		{
+			x: []float64{1, 3, 5, 7},
+			y: []float64{2, 4, 6, 8},
+			u: 6, p: 0.68571429,
+		},
+		{
+			x: []float64{10.1, 10.3, 9.9, 10.0},
+			y: []float64{10.2, 10.05, 10.15, 10.35, 10.4},
+			u: 4, p: 0.19047619,
+		},
+		// Ties use the corrected normal approximation.
+		{
+			x: []float64{1, 2, 2, 3},
+			y: []float64{2, 3, 3, 4, 5},
+			u: 3, p: 0.099342248,
+		},
+		{
+			x: []float64{1, 1, 1, 1, 2, 2},
+			y: []float64{2, 2, 3, 3, 3, 3},
+			u: 2, p: 0.0087060217,
+		},
+		// Constant samples.
+		{x: []float64{5, 5}, y: []float64{5, 5, 5}, u: 3, p: 1},
+	}
+	for _, tt := range cases {
+		u, p, err := MannWhitneyUTest(tt.x, tt.y)
+		if err != nil {
+			t.Errorf("MannWhitneyUTest(%v, %v) failed: %v", tt.x, tt.y, err)
+			continue
+		}
+		if u != tt.u || !approxEqual(p, tt.p) {
+			t.Errorf("MannWhitneyUTest(%v, %v): want u=%g p=%g have u=%g p=%g", tt.x, tt.y, tt.u, tt.p, u, p)
+		}
+	}
+
+	if _, _, err := MannWhitneyUTest([]float64{1}, []float64{1, 2}); err == nil {
+		t.Errorf("MannWhitneyUTest with one sample should have failed")
+	}
+}
+
+func TestRankSum(t *testing.T) {
+	cases := []struct {
+		x, y      []float64
+		sum, ties float64
+	}{
+		{x: []float64{3, 1}, y: []float64{2, 4}, sum: 4, ties: 0},
+		// 1 2 2 2 3 are ranked 1 3 3 3 5.
+		{x: []float64{2, 1, 2}, y: []float64{3, 2}, sum: 7, ties: 24},
+		{x: []float64{7, 7}, y: []float64{7, 7}, sum: 5, ties: 60},
+	}
+	for _, tt := range cases {
+		sum, ties := rankSum(tt.x, tt.y)
+		if sum != tt.sum || ties != tt.ties {
+			t.Errorf("rankSum(%v, %v): want %g, %g have %g, %g", tt.x, tt.y, tt.sum, tt.ties, sum, ties)
+		}
+	}
+}
+
+func TestMedian(t *testing.T) {
+	cases := []struct {
+		x    []float64
+		want float64
+	}{
+		{x: []float64{5}, want: 5},
+		{x: []float64{3, 1, 2}, want: 2},
+		{x: []float64{10, 10, 11, 1000}, want: 10.5},
+		{x: []float64{4, 1, 3, 2}, want: 2.5},
+	}
+	for _, tt := range cases {
+		x := append([]float64(nil), tt.x...)
+		if have := Median(x); have != tt.want {
+			t.Errorf("Median(%v): want %g have %g", tt.x, tt.want, have)
+		}
+		if !reflect.DeepEqual(x, tt.x) {
+			t.Errorf("Median(%v) modified its argument", tt.x)
+		}
+	}
+}
+
+func TestStdDev(t *testing.T) {
+	cases := []struct {
+		x       []float64
+		sd, cov float64
+	}{
+		{x: []float64{2, 4, 4, 4, 5, 5, 7, 9}, sd: 2.13809, cov: 0.427618},
+		{x: []float64{-2, -4}, sd: 1.41421, cov: 0.471405},
+		{x: []float64{3, 3, 3}, sd: 0, cov: 0},
+		{x: []float64{-1, 1}, sd: 1.41421, cov: 0}, // zero mean
+	}
+	for _, tt := range cases {
+		if sd := StdDev(tt.x); !approxEqual(sd, tt.sd) {
+			t.Errorf("StdDev(%v): want %g have %g", tt.x, tt.sd, sd)
+		}
+		if cov := CoV(tt.x); !approxEqual(cov, tt.cov) {
+			t.Errorf("CoV(%v): want %g have %g", tt.x, tt.cov, cov)
+		}
+	}
+
+	b := &Bench{Samples: map[string][]float64{"ns/op": {90, 110}, "B/op": {8}}}
+	if rsd, ok := b.RelStdDev("ns/op"); !ok || !approxEqual(rsd, 0.141421) {
+		t.Errorf("RelStdDev(ns/op): want 0.141421, true have %g, %t", rsd, ok)
+	}
+	if _, ok := b.RelStdDev("B/op"); ok {
+		t.Errorf("RelStdDev(B/op) with one sample: want false have true")
+	}
+	if _, ok := b.RelStdDev("MB/s"); ok {
+		t.Errorf("RelStdDev(MB/s) without samples: want false have true")
+	}
+}
+
+// approxEqual reports whether x and y agree to about five significant digits.
+func approxEqual(x, y float64) bool {
+	if x == y {
+		return true
+	}
+	return math.Abs(x-y) <= 1e-5*math.Max(math.Abs(x), math.Abs(y))
+}
@@ -0,0 +1,143 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A tableWriter aligns tab-terminated cells into columns, like a
+// text/tabwriter.Writer padding with spaces, but measures each cell by
+// its width on a terminal rather than by its number of runes, so that
+// names holding wide or combining characters stay aligned. Output is
+// buffered until Flush is called.
+type tableWriter struct {
+	out      io.Writer
+	minwidth int // least width of a column, padding included
+	padding  int // spaces added to the widest cell of each column
+	buf      bytes.Buffer
+}
+
+func newTableWriter(out io.Writer, minwidth, padding int) *tableWriter {
+	return &tableWriter{out: out, minwidth: minwidth, padding: padding}
+}
+
+func (t *tableWriter) Write(p []byte) (int, error) {
+	return t.buf.Write(p)
+}
+
+// Flush aligns and writes the buffered text. As with text/tabwriter, a
+// column is aligned over each run of adjacent lines that have a cell in
+// it, and the text after the last tab of a line is not part of a cell.
+func (t *tableWriter) Flush() error {
+	lines := strings.Split(t.buf.String(), "\n")
+	t.buf.Reset()
+	cells := make([][]string, len(lines))
+	for i, line := range lines {
+		cells[i] = strings.Split(line, "\t")
+	}
+	// widths[i][c] is the width to which cell c of line i is padded.
+	widths := make([][]int, len(lines))
+	for i := range cells {
+		widths[i] = make([]int, len(cells[i])-1)
+	}
+	for c := 0; ; c++ {
+		more := false
+		for i := 0; i < len(lines); {
+			if c >= len(widths[i]) {
+				i++
+				continue
+			}
+			more = true
+			j, width := i, t.minwidth
+			for ; j < len(lines) && c < len(widths[j]); j++ {
+				if w := displayWidth(cells[j][c]) + t.padding; w > width {
+					width = w
+				}
+			}
+			for ; i < j; i++ {
+				widths[i][c] = width
+			}
+		}
+		if !more {
+			break
+		}
+	}
+
+	var out bytes.Buffer
+	for i := range lines {
+		if i > 0 {
+			out.WriteByte('\n')
+		}
+		for c, w := range widths[i] {
+			out.WriteString(cells[i][c])
+			out.WriteString(strings.Repeat(" ", w-displayWidth(cells[i][c])))
+		}
+		out.WriteString(cells[i][len(cells[i])-1])
+	}
+	_, err := t.out.Write(out.Bytes())
+	return err
+}
+
+// displayWidth returns the number of columns s occupies on a terminal.
+// ANSI escape sequences, combining marks, and format characters take no
+// space, and East Asian wide characters take two columns.
+func displayWidth(s string) int {
+	n := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '[' {
+			// Skip a control sequence, which ends with a byte
+			// in the range @ to ~.
+			i += 2
+			for i < len(s) && (s[i] < '@' || s[i] > '~') {
+				i++
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		case isWide(r):
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// wideRanges lists the ranges of East Asian wide and fullwidth characters.
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115F},   // Hangul Jamo initial consonants
+	{0x2E80, 0x303E},   // CJK radicals, symbols and punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK unified ideographs extension A
+	{0x4E00, 0x9FFF},   // CJK unified ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE30, 0xFE4F},   // CJK compatibility forms
+	{0xFF00, 0xFF60},   // fullwidth forms
+	{0xFFE0, 0xFFE6},   // fullwidth signs
+	{0x1F300, 0x1F64F}, // pictographs and emoticons
+	{0x1F900, 0x1F9FF}, // supplemental pictographs
+	{0x20000, 0x3FFFD}, // CJK ideographs in planes 2 and 3
+}
+
+// isWide reports whether r takes two columns on a terminal.
+func isWide(r rune) bool {
+	for _, wr := range wideRanges {
+		if r >= wr.lo && r <= wr.hi {
+			return true
+		}
+	}
+	return false
+}
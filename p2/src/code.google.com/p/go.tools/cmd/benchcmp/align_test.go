@@ -0,0 +1,89 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	cases := []struct {
+		s    string
+		want int
+	}{
+		{"BenchmarkEncrypt", 16},
+		{"BenchmarkÜber", 13},
+		{"BenchmarkÜber", 13}, // U followed by a combining diaeresis
+		{"Benchmark日本語", 15},
+		{"\x1b[31m+5.00%\x1b[0m", 6},
+		{"", 0},
+	}
+	for _, tt := range cases {
+		if have := displayWidth(tt.s); tt.want != have {
+			t.Errorf("displayWidth(%q): want %d have %d", tt.s, tt.want, have)
+		}
+	}
+}
+
+func TestTableWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := newTableWriter(buf, 0, 2)
+	w.Write([]byte("a\tbb\tc\t\nccc\td\t\n\nx\ty\n"))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	want := "a    bb  c  \nccc  d   \n\nx  y\n"
+	if have := buf.String(); want != have {
+		t.Errorf("tableWriter: want %q have %q", want, have)
+	}
+}
+
+func TestTableWriterMinWidth(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := newTableWriter(buf, 4, 1)
+	w.Write([]byte("a\tbbbb\tc\n"))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	want := "a   bbbb c\n"
+	if have := buf.String(); want != have {
+		t.Errorf("tableWriter: want %q have %q", want, have)
+	}
+}
+
+func TestUnicodeAlignment(t *testing.T) {
+	names := []string{"BenchmarkEncrypt", "BenchmarkÜberschrift", "Benchmark暗号化/日本語", "BenchmarkÜber"}
+	var cmps []benchcmp.BenchCmp
+	for _, name := range names {
+		cmps = append(cmps, benchcmp.BenchCmp{
+			Before: &benchcmp.Bench{Name: name, NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: name, NsOp: 110, Measured: benchcmp.NsOp},
+		})
+	}
+	buf := new(bytes.Buffer)
+	if err := renderText(buf, cmps); err != nil {
+		t.Fatalf("renderText failed: %v", err)
+	}
+
+	// The old value of every benchmark, ASCII-only or not,
+	// must begin at the same column.
+	col := -1
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n")[1:] {
+		i := strings.Index(line, "100")
+		if i < 0 {
+			t.Fatalf("no old value in %q", line)
+		}
+		w := displayWidth(line[:i])
+		if col < 0 {
+			col = w
+		} else if w != col {
+			t.Errorf("misaligned row %q: old value at column %d, want %d", line, w, col)
+		}
+	}
+}
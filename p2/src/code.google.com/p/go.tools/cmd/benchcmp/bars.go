@@ -0,0 +1,51 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"strings"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// barWidth is the length of the longest bar drawn by -bars
+// on either side of its axis.
+const barWidth = 10
+
+// barScale returns the largest finite magnitude of the changes in rows
+// of the measurement described by s, which bar draws at full length.
+func (s section) barScale(rows []benchcmp.BenchCmp) float64 {
+	var max float64
+	for _, cmp := range rows {
+		if w := math.Abs(s.worsening(s.delta(cmp))); !math.IsInf(w, 0) && w > max {
+			max = w
+		}
+	}
+	return max
+}
+
+// bar draws a bar whose length is proportional to the magnitude of w,
+// a worsening in percent, relative to max. Regressions extend to the
+// right of the axis and improvements to the left. A change from zero
+// is drawn at full length.
+func bar(w, max float64) string {
+	n := barWidth
+	if !math.IsInf(w, 0) {
+		if max == 0 {
+			n = 0
+		} else {
+			n = int(math.Abs(w)/max*barWidth + 0.5)
+		}
+	}
+	left, right := strings.Repeat(" ", barWidth), strings.Repeat(" ", barWidth)
+	switch {
+	case w < 0:
+		left = strings.Repeat(" ", barWidth-n) + strings.Repeat("█", n)
+	case w > 0:
+		right = strings.Repeat("█", n) + strings.Repeat(" ", barWidth-n)
+	}
+	return left + "|" + right
+}
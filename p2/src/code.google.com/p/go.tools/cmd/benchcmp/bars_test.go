@@ -0,0 +1,43 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestBar(t *testing.T) {
+	cases := []struct {
+		w, max float64
+		want   string
+	}{
+		{w: 20, max: 20, want: "          |██████████"},
+		{w: -10, max: 20, want: "     █████|          "},
+		{w: 1, max: 20, want: "          |█         "},
+		{w: 0.4, max: 20, want: "          |          "},
+		{w: 0, max: 0, want: "          |          "},
+		{w: math.Inf(1), max: 5, want: "          |██████████"},
+		{w: math.Inf(1), max: 0, want: "          |██████████"},
+	}
+	for _, tt := range cases {
+		if have := bar(tt.w, tt.max); tt.want != have {
+			t.Errorf("bar(%g, %g): want %q have %q", tt.w, tt.max, tt.want, have)
+		}
+	}
+}
+
+func TestBarScale(t *testing.T) {
+	rows := []benchcmp.BenchCmp{
+		{Before: &benchcmp.Bench{NsOp: 100}, After: &benchcmp.Bench{NsOp: 110}},
+		{Before: &benchcmp.Bench{NsOp: 100}, After: &benchcmp.Bench{NsOp: 70}},
+		{Before: &benchcmp.Bench{NsOp: 0}, After: &benchcmp.Bench{NsOp: 70}},
+	}
+	if want, have := 30.0, sections[0].barScale(rows); math.Abs(want-have) > 1e-9 {
+		t.Errorf("barScale: want %g have %g", want, have)
+	}
+}
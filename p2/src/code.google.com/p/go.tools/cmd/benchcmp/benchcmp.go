@@ -6,24 +6,214 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"regexp"
 	"sort"
 	"strconv"
-	"text/tabwriter"
+	"strings"
+	"time"
+
+	"code.google.com/p/go.tools/benchcmp"
 )
 
 var (
-	changedOnly = flag.Bool("changed", false, "show only benchmarks that have changed")
 	magSort     = flag.Bool("mag", false, "sort benchmarks by magnitude of change")
+	top         = flag.Int("top", 0, "show only the N largest changes in each table; implies -mag")
+	maxDelta    = flag.Float64("max-delta", 0, "with -sort=mag or -sort=delta, sort changes beyond this percentage as if they were at it, marking them with >")
+	seedOrder   = flag.Int64("seed-order", 0, "with -sort=mag or -sort=delta, order benchmarks whose changes tie by a shuffle chosen by this nonzero seed")
+	sortKey     = flag.String("sort-key", "", "with -mag, sort the rows of every table by the change in this metric, such as nsop, so that each benchmark keeps its row across tables")
+	sortBy      = flag.String("sort", "", "sort benchmarks by: name, mag, or delta, from worst regression to best improvement (default parse order)")
+	reverse     = flag.Bool("reverse", false, "reverse the sort order, as to show the smallest changes first with -mag")
+	format      = flag.String("format", "text", "output format: text, json, csv, github, html, influx, junit, markdown, prom, stable, tsv, or yaml")
+	showGeoMean = flag.Bool("geomean", false, "show the geometric mean of the changes in each table")
+	weighted    = flag.Bool("weighted", false, "with -geomean, weight each benchmark by its share of the total time of the old run")
+	split       = flag.String("split", "", "group text output by benchmark name suffix: gomaxprocs")
+	group       = flag.String("group", "", "group text output by benchmark name prefix, before the first _ or /, with the ns/op geomean of each: prefix")
+	filter      = flag.String("filter", "", "compare only benchmarks whose names match this regular expression")
+	exclude     = flag.String("exclude", "", "do not compare benchmarks whose names match this regular expression, even if they match -filter")
+	prefixStrip = flag.String("prefix-strip", "", "regular expression matching a log prefix, such as a timestamp, to remove from the start of each input line")
+	ciMode      = flag.Bool("ci", false, "exit with status 1 if any benchmark regresses by more than -threshold")
+	worst       = flag.Bool("worst", false, "print only the benchmark whose ns/op regressed the most beyond -threshold, exiting with status 1 if there is one")
+	failOn      = flag.String("fail-on", "regression", "changes beyond -threshold that fail -ci: regression, any (in either direction), or none")
+	ratio       = flag.Bool("ratio", false, "also show the ratio of new to old ns/op, allocs, and bytes, as in 0.80 for 20% faster")
+	absDelta    = flag.Bool("abs", false, "also show the absolute change in ns/op, allocs, and bytes")
+	opsPerSec   = flag.Bool("opspersec", false, "also show operations per second, derived from ns/op")
+	showTime    = flag.Bool("time", false, "also show the total time of each benchmark, iterations times ns/op")
+	allocRate   = flag.Bool("allocrate", false, "also show the bytes allocated per second, derived from B/op and ns/op")
+	allocsRate  = flag.Bool("allocspersec", false, "also show the allocations per second, derived from allocs/op and ns/op")
+	showStdDev  = flag.Bool("stddev", false, "show the relative standard deviation of repeated runs")
+	normalizeTo = flag.String("normalize", "", "divide the ns/op, MB/s, and custom metrics of each run by those of the named benchmark in it")
+	renameFile  = flag.String("rename", "", "file of old=new lines renaming benchmarks in the old file")
+	strict      = flag.Bool("strict", false, "fail, listing every problem, if any input file has malformed, negative, or duplicated benchmark results, instead of warning")
+	force       = flag.Bool("force", false, "do not warn about comparing runs from different platforms or packages")
+	minMatch    = flag.Float64("minmatch", 20, "warn if fewer than this percentage of the benchmarks in the smaller file match")
+	metric      = flag.String("metric", "", "comma-separated list of metrics to show, such as allocs,bytes (default all)")
+	aggregate   = flag.String("aggregate", "mean", "how to summarize repeated runs of a benchmark: mean or median")
+	trim        = flag.Float64("trim", 0, "percentage of the repeated runs of each benchmark to discard, slowest first")
+	trimFast    = flag.Bool("trimfast", false, "with -trim, also discard the fastest runs")
+	minSamples  = flag.Int("min-samples", 0, "warn, or fail -ci, if a benchmark has fewer than N runs in any file compared")
+	fuzzy       = flag.Bool("fuzzy", false, "match benchmarks whose names differ only in case or separators")
+	since       = flag.Bool("since", false, "compare the newest of the files in a directory, or matching a glob pattern, with the oldest, by modification time")
+	rangeN      = flag.Int("range", 1, "with -since, compare the newest file with the Nth oldest instead")
+	watch       = flag.Bool("watch", false, "compare again, clearing the screen, whenever the new file is rewritten")
+	outPath     = flag.String("o", "", "write the comparison to this file instead of standard output")
+	mbPercent   = flag.Bool("mbpercent", false, "show the change in MB/s as a percentage rather than a speedup")
+	newOnly     = flag.Bool("new-only", false, "list the measured values of the benchmarks in the new file but not the old, instead of comparing them")
+	showSummary = flag.Bool("summary", false, "summarize the benchmarks of a single file instead of comparing files")
+	matrix      = flag.String("matrix", "", "show the ns/op of each benchmark of a single file at each GOMAXPROCS of go test -cpu: cpu")
+	maxWarnings = flag.Int("max-warnings", 0, "list at most N items of each warning, such as the benchmarks found in only one file, and count the rest (default all)")
+	quiet       = flag.Bool("q", false, "do not print warnings, such as about benchmarks found in only one file")
+	colorMode   = flag.String("color", "auto", "color changes in text output: auto, always, or never")
+	showHist    = flag.Bool("hist", false, "also show a histogram of the changes in ns/op")
+	histSpec    = flag.String("histbounds", "-20,-10,-5,0,5,10,20", "comma-separated percentages dividing the buckets of -hist")
+	showBars    = flag.Bool("bars", false, "draw a bar showing the size and direction of each change in text output")
+	profileBase = flag.String("profile-base", "", "in html and markdown output, link each regressed benchmark to its CPU profile at this URL, as URL/name.prof")
+	padding     = flag.Int("padding", 5, "pad each column of text tables with N spaces beyond its widest cell")
+	minWidth    = flag.Int("minwidth", 0, "make each column of text tables at least N columns wide, padding included")
+	showHeader  = flag.Bool("header", false, "begin the output with the files compared, their modification times, and the time now, in json, yaml, csv, and tsv too, and begin each warning with the time")
+	timestamp   = flag.String("timestamp", "", "time of the points written by -format=influx, in RFC 3339 format or Unix seconds (default now)")
+	precision   = flag.Int("precision", -1, "number of decimal places of ns/op values (default as chosen by go test for their magnitude)")
+	multPrec    = flag.Int("mult-precision", 2, "number of decimal places of speedups, such as that of MB/s")
+	contextN    = flag.Int("context", 0, "with -changed, also show up to N unchanged benchmarks before and after each changed one, in parse order")
+	minReport   = flag.Int("minreport", 1, "omit the table of a measurement reported in both runs by fewer than N benchmarks")
+	showCounts  = flag.Bool("counts", false, "end text output with the number of benchmarks improved, regressed, and unchanged in each table")
+	totalBOp    = flag.Bool("totalbytes", false, "end text output with the change in the B/op summed over the benchmarks that measured it in both runs")
+	confidence  = flag.Float64("confidence", defaultConfidence, "confidence level at which a change in repeated runs is significant")
+	sigTest     = flag.String("test", "ttest", "significance test for repeated runs: ttest (Welch's t-test) or utest (Mann-Whitney U test)")
 )
 
+// filterRE is the compiled -filter expression, or nil.
+var filterRE *regexp.Regexp
+
+// excludeRE is the compiled -exclude expression, or nil.
+var excludeRE *regexp.Regexp
+
+// metrics holds the metrics selected by -metric, keyed by name,
+// or nil to show all of them.
+var metrics map[string]bool
+
+// aggregators holds the ways of summarizing repeated runs, keyed by
+// -aggregate name.
+var aggregators = map[string]func([]float64) float64{
+	"mean":   benchcmp.Mean,
+	"median": benchcmp.Median,
+}
+
+// tests holds the significance tests of repeated runs, keyed by
+// -test name.
+var tests = map[string]func(x, y []float64) (float64, float64, error){
+	"ttest": benchcmp.WelchTTest,
+	"utest": benchcmp.MannWhitneyUTest,
+}
+
+// stdout is where the comparison is written: standard output,
+// or the file named by -o.
+var stdout = os.Stdout
+
+// stderr is where warnings are written: standard error,
+// or nowhere with -q.
+var stderr io.Writer = os.Stderr
+
+// signedSort reports whether -sort=delta orders the benchmarks sorted by
+// change from the worst regression to the best improvement, rather than
+// by magnitude of change as with -mag.
+var signedSort bool
+
+// renames holds the benchmark renames read from -rename.
+var renames map[string]string
+
 const usageFooter = `
 Each input file should be from:
 	go test -test.run=NONE -test.bench=. > [old,new].txt
 
-Benchcmp compares old and new for each benchmark.
+A file may be a comma-separated list of files, such as
+one for each package, whose benchmarks form one run:
+	benchcmp old.txt new-aes.txt,new-sha1.txt
+
+A file may be - to read it from standard input:
+	go test -test.run=NONE -test.bench=. | benchcmp old.txt -
+Given for both files, - compares the input with itself, as
+does any file named twice, which should show no changes.
+Input compressed with gzip is decompressed automatically.
+Benchmark results within a larger log, such as that of a
+CI job, are found among its other output, and -prefix-strip
+removes a prefix, such as a timestamp, from each line:
+	benchcmp -prefix-strip='\S+Z ' ci-old.log ci-new.log
+The output of -format=json may also be given as the old file,
+to compare against the new side of a saved comparison.
+The output of go test -json is read as well.
+So is the output of benchstat for a single file, whose
+means are compared as if each benchmark ran once.
+
+Benchcmp compares old and new for each benchmark,
+including any custom metrics reported by b.ReportMetric.
+Percentile latencies such as p99_ns/op are shown like ns/op,
+and an increase in them is a regression.
+Repeated runs of a benchmark, as from go test -count,
+are averaged (or, with -aggregate=median, summarized
+by their median), and changes that are not statistically
+significant are shown as ~. Significance is judged by
+Welch's t-test or, with -test=utest, by the Mann-Whitney
+U test, which does not assume the runs are normally
+distributed. With -min-samples, benchcmp warns about, or
+under -ci fails on, benchmarks with too few runs in any
+file compared for those tests to mean much.
+If more than two files are given, benchcmp shows each
+benchmark across all of them, and the change from the
+first to the last.
+With -since, benchcmp is given a directory of logs, or a
+glob pattern matching them, and compares the newest with
+the oldest, or with the Nth oldest given -range=N.
+With -strict, benchcmp refuses to compare files with any
+malformed, negative, or duplicated results, listing them all,
+rather than warning and comparing the rest.
+With -worst, benchcmp prints only the benchmark whose ns/op
+regressed the most beyond -threshold, if any, and exits with
+status 1 if there is one.
+With -new-only, benchcmp instead lists the benchmarks
+added in the new file, with their measured values.
+With -rename, benchmarks renamed since the old file
+are compared under their new names; the file holds
+one old=new line per renamed benchmark.
+With -geomean -weighted, the mean of the changes weights
+each benchmark by its share of the total time of the old
+run, iterations times ns/op: exp(Σ wᵢ·log rᵢ / Σ wᵢ) for
+changes rᵢ and weights wᵢ, so that it reflects where the
+time is spent.
+With -normalize=BenchmarkBase, each run's measurements are
+divided by those of BenchmarkBase in the same run, so that
+runs on machines of different speeds compare as ratios.
+With -allocrate, benchcmp also shows the bytes allocated
+per second, B/op divided by ns/op, for benchmarks run
+with -test.benchmem=true, and with -allocspersec, the
+allocations per second, allocs/op divided by ns/op.
+Given one file of benchmarks run by go test -cpu=1,2,4,
+-matrix=cpu shows the ns/op of each at each GOMAXPROCS.
+Given two directories, benchcmp compares each file in
+the old directory with the file of the same name in the
+new one, such as the benchmarks of one package each.
+The -threshold may be set per metric, as in
+-threshold=3,ns=5,allocs=0, where 3 is the default for
+metrics not listed. When -threshold is given, -changed
+also hides changes within the threshold.
+With -changed=regressed or -changed=improved, only the
+changes beyond the threshold in that direction are shown,
+judged as by -ci. Like grep -C, -context=N also shows the
+N benchmarks before and after each change, in parse order.
+With -ci, -fail-on chooses which changes beyond their
+metric's threshold fail: regressions (the default), any
+change in either direction, or none, which still prints
+the comparison but always exits with status 0. The
+thresholds decide whether a change counts; -fail-on
+decides only which of the changes that count fail.
 
 If -test.benchmem=true is added to the "go test" command
 benchcmp will also compare memory allocations.
@@ -31,103 +221,1334 @@ benchcmp will also compare memory allocations.
 
 func main() {
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "usage: %s old.txt new.txt\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "usage: %s old.txt [mid.txt ...] new.txt\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s -summary file.txt\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s -matrix=cpu file.txt\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s -since dir|pattern\n\n", os.Args[0])
 		flag.PrintDefaults()
 		fmt.Fprint(os.Stderr, usageFooter)
 		os.Exit(2)
 	}
 	flag.Parse()
-	if flag.NArg() != 2 {
+	single := *showSummary || *matrix != "" || *since
+	if single && flag.NArg() != 1 || !single && flag.NArg() < 2 {
 		flag.Usage()
 	}
+	if *rangeN < 1 {
+		fatal("benchcmp: -range must be at least 1")
+	}
+	if *rangeN != 1 && !*since {
+		fatal("benchcmp: -range requires -since")
+	}
+	if *since && (*showSummary || *matrix != "") {
+		fatal("benchcmp: -since cannot be used with -summary or -matrix")
+	}
+	if *matrix != "" && *matrix != "cpu" {
+		fatal(fmt.Sprintf("benchcmp: unknown matrix %q; want cpu", *matrix))
+	}
+	if *matrix != "" && *showSummary {
+		fatal("benchcmp: -matrix and -summary cannot be used together")
+	}
+	render, ok := renderers[*format]
+	if !ok {
+		fatal(fmt.Sprintf("benchcmp: unknown format %q", *format))
+	}
+	if aggregators[*aggregate] == nil {
+		fatal(fmt.Sprintf("benchcmp: unknown aggregate %q; want mean or median", *aggregate))
+	}
+	if failModes[*failOn] == nil {
+		fatal(fmt.Sprintf("benchcmp: unknown -fail-on %q; want regression, any, or none", *failOn))
+	}
+	if tests[*sigTest] == nil {
+		fatal(fmt.Sprintf("benchcmp: unknown test %q; want ttest or utest", *sigTest))
+	}
+	if *confidence <= 0 || *confidence >= 1 {
+		fatal("benchcmp: -confidence must be between 0 and 1")
+	}
+	alpha = 1 - *confidence
+	if *trim < 0 || *trim >= 100 || *trimFast && *trim >= 50 {
+		fatal("benchcmp: -trim must leave some runs of each benchmark")
+	}
+	if *multPrec < 0 {
+		fatal("benchcmp: -mult-precision must not be negative")
+	}
+	if *minSamples < 0 {
+		fatal("benchcmp: -min-samples must not be negative")
+	}
+	if *metric != "" {
+		metrics = make(map[string]bool)
+		for _, name := range strings.Split(*metric, ",") {
+			name = strings.TrimSpace(name)
+			if !validMetric(name) {
+				fatal(fmt.Sprintf("benchcmp: unknown metric %q; valid metrics are %s, or the unit of a custom metric, such as items/op", name, strings.Join(metricNames(), ", ")))
+			}
+			metrics[name] = true
+		}
+	}
+	if *filter != "" {
+		re, err := regexp.Compile(*filter)
+		if err != nil {
+			fatal(fmt.Sprintf("benchcmp: invalid -filter: %v", err))
+		}
+		filterRE = re
+	}
+	if *exclude != "" {
+		re, err := regexp.Compile(*exclude)
+		if err != nil {
+			fatal(fmt.Sprintf("benchcmp: invalid -exclude: %v", err))
+		}
+		excludeRE = re
+	}
+	if *prefixStrip != "" {
+		re, err := compilePrefix(*prefixStrip)
+		if err != nil {
+			fatal(fmt.Sprintf("benchcmp: invalid -prefix-strip: %v", err))
+		}
+		prefixRE = re
+	}
+	if *quiet {
+		stderr = ioutil.Discard
+	}
+	if *showHist {
+		if *format != "text" {
+			fatal(fmt.Sprintf("benchcmp: -hist cannot be used with -format=%s", *format))
+		}
+		var err error
+		histBounds, err = parseBounds(*histSpec)
+		if err != nil {
+			fatal(fmt.Sprintf("benchcmp: invalid -histbounds: %v", err))
+		}
+	}
+	if *timestamp != "" {
+		t, err := parseTimestamp(*timestamp)
+		if err != nil {
+			fatal(fmt.Sprintf("benchcmp: invalid -timestamp %q; want a time such as 2014-01-02T15:04:05Z or Unix seconds", *timestamp))
+		}
+		influxTime = t
+	}
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			fatal(err)
+		}
+		stdout = f
+	}
+	switch *colorMode {
+	case "auto":
+		useColor = isTerminal(stdout)
+	case "always":
+		useColor = true
+	case "never":
+	default:
+		fatal(fmt.Sprintf("benchcmp: unknown color mode %q", *colorMode))
+	}
+	if *mbPercent {
+		showMbPercent()
+	}
+	switch *sortBy {
+	case "", "name":
+	case "mag":
+		*magSort = true
+	case "delta":
+		*magSort = true
+		signedSort = true
+	default:
+		fatal(fmt.Sprintf("benchcmp: unknown sort %q", *sortBy))
+	}
+	if *top < 0 {
+		fatal("benchcmp: -top must not be negative")
+	}
+	if *padding < 0 || *minWidth < 0 {
+		fatal("benchcmp: -padding and -minwidth must not be negative")
+	}
+	if *maxWarnings < 0 {
+		fatal("benchcmp: -max-warnings must not be negative")
+	}
+	if *contextN < 0 {
+		fatal("benchcmp: -context must not be negative")
+	}
+	if *maxDelta < 0 {
+		fatal("benchcmp: -max-delta must not be negative")
+	}
+	if *top > 0 {
+		*magSort = true
+	}
+	if *sortKey != "" {
+		if !validSortKey(*sortKey) {
+			fatal(fmt.Sprintf("benchcmp: unknown -sort-key %q; valid metrics are %s, their units, or the unit of a custom metric", *sortKey, strings.Join(metricNames(), ", ")))
+		}
+		*magSort = true
+	}
+	if *split != "" && *split != "gomaxprocs" {
+		fatal(fmt.Sprintf("benchcmp: unknown split %q", *split))
+	}
+	if *group != "" && *group != "prefix" {
+		fatal(fmt.Sprintf("benchcmp: unknown group %q", *group))
+	}
+	if *group != "" && *split != "" {
+		fatal("benchcmp: -group and -split cannot be used together")
+	}
+	args := flag.Args()
+	if *since {
+		oldPath, newPath, err := sinceFiles(args[0], *rangeN)
+		if err != nil {
+			fatal("benchcmp: " + err.Error())
+		}
+		args = []string{oldPath, newPath}
+	}
+	if *showHeader {
+		if !headerFormats[*format] {
+			fatal(fmt.Sprintf("benchcmp: -header cannot be used with -format=%s", *format))
+		}
+		inputs = newInputs(args)
+		stderr = &stampWriter{w: stderr, now: time.Now}
+	}
+	if *format != "text" && len(args) > 2 {
+		fatal(fmt.Sprintf("benchcmp: -format=%s requires exactly two files", *format))
+	}
+	if *renameFile != "" {
+		var err error
+		renames, err = readRenames(*renameFile)
+		if err != nil {
+			fatal(fmt.Sprintf("benchcmp: -rename: %v", err))
+		}
+	}
+	stdin := 0
+	for _, path := range args {
+		if path == "-" {
+			stdin++
+		}
+	}
+	stdinTwice = stdin > 1
 
-	before := parseFile(flag.Arg(0))
-	after := parseFile(flag.Arg(1))
+	if *watch {
+		if len(args) != 2 || isDir(args[0]) || isDir(args[1]) || stdin > 0 {
+			fatal("benchcmp: -watch requires two files")
+		}
+		if *outPath != "" || *ciMode {
+			fatal("benchcmp: -watch cannot be used with -o or -ci")
+		}
+		watchFiles(args[0], args[1], render)
+	}
+	if *matrix != "" {
+		log := parseFile(args[0])
+		if err := renderMatrix(stdout, pivotCPU(log.Benchmarks)); err != nil {
+			fatal(err)
+		}
+		closeOutput()
+		return
+	}
+	if *showSummary {
+		log := parseFile(args[0])
+		if err := renderSummary(stdout, args[0], summarize(log.Benchmarks)); err != nil {
+			fatal(err)
+		}
+		closeOutput()
+		return
+	}
+	if *worst && (len(args) != 2 || isDir(args[0]) || isDir(args[1]) || *format != "text") {
+		fatal("benchcmp: -worst requires two files and text output")
+	}
+	if *newOnly {
+		if len(args) != 2 || isDir(args[0]) || isDir(args[1]) {
+			fatal("benchcmp: -new-only requires two files")
+		}
+		if *format != "text" || *ciMode {
+			fatal("benchcmp: -new-only cannot be used with -format or -ci")
+		}
+		bb, err := newBenchmarks(args[0], args[1])
+		if err != nil {
+			fatal("benchcmp: " + err.Error())
+		}
+		if err := renderNewOnly(stdout, bb); err != nil {
+			fatal(err)
+		}
+		closeOutput()
+		return
+	}
+	if len(args) > 2 {
+		cmps := compareN(args)
+		if *ciMode {
+			spans := make([]benchcmp.BenchCmp, len(cmps))
+			for i, cmp := range cmps {
+				spans[i] = cmp.Span()
+			}
+			checkSamples(fewSamplesN(cmps, *minSamples))
+			checkRegressions(spans)
+		}
+		return
+	}
+	if dirs := isDir(args[0]); dirs || isDir(args[1]) {
+		if !dirs || !isDir(args[1]) {
+			fatal("benchcmp: cannot compare a directory with a file")
+		}
+		if *format != "text" {
+			fatal(fmt.Sprintf("benchcmp: -format=%s cannot compare directories", *format))
+		}
+		writeTextHeader(stdout)
+		all := compareDirs(args[0], args[1])
+		if *ciMode {
+			checkSamples(fewSamples(all, *minSamples))
+			checkRegressions(all)
+		}
+		return
+	}
 
-	cmps, warnings := Correlate(before, after)
+	cmps, err := compare(args[0], args[1])
+	if err != nil {
+		fatal("benchcmp: " + err.Error())
+	}
+	if *worst {
+		r, found := worstRegression(cmps)
+		if found {
+			fmt.Fprintln(stdout, r)
+		}
+		closeOutput()
+		if found {
+			os.Exit(1)
+		}
+		return
+	}
 
-	for _, warn := range warnings {
-		fmt.Fprintln(os.Stderr, warn)
+	writeTextHeader(stdout)
+	output(render, cmps)
+	if *showHist {
+		fmt.Fprintln(stdout)
+		if err := renderHist(stdout, cmps); err != nil {
+			fatal(err)
+		}
 	}
+	closeOutput()
 
-	if len(cmps) == 0 {
-		fatal("benchcmp: no repeated benchmarks")
+	if *ciMode {
+		checkSamples(fewSamples(cmps, *minSamples))
+		checkRegressions(cmps)
 	}
+}
 
-	w := new(tabwriter.Writer)
-	w.Init(os.Stdout, 0, 0, 5, ' ', 0)
-	defer w.Flush()
+// compare compares the benchmarks in the files at oldPath and newPath,
+// reporting any problems with them to standard error, and returns the
+// comparisons selected by -filter and -exclude. It returns an error if
+// there are none.
+func compare(oldPath, newPath string) ([]benchcmp.BenchCmp, error) {
+	return compareLogs(oldPath, newPath, parseFiles(oldPath, newPath))
+}
 
-	var header bool // Has the header has been displayed yet for a given block?
+// compareLogs is like compare, but compares logs, those of the files at
+// oldPath and newPath as returned by parseFiles.
+func compareLogs(oldPath, newPath string, logs []*benchcmp.Log) ([]benchcmp.BenchCmp, error) {
+	oldLog, newLog := logs[0], logs[1]
+	if err := checkEmpty([]string{oldPath, newPath}, logs); err != nil {
+		return nil, err
+	}
+	if !*force {
+		warnConfig([]string{oldPath, newPath}, []*benchcmp.Log{oldLog, newLog})
+	}
+	before := benchcmp.Rename(oldLog.Benchmarks, renames)
+	after := newLog.Benchmarks
+	if *normalizeTo != "" {
+		var err error
+		if before, err = normalize(oldPath, before, *normalizeTo); err != nil {
+			return nil, err
+		}
+		if after, err = normalize(newPath, after, *normalizeTo); err != nil {
+			return nil, err
+		}
+	}
+	if *fuzzy {
+		before = fuzzyRename(before, after)
+	}
 
-	if *magSort {
-		sort.Sort(ByDeltaNsOp(cmps))
-	} else {
-		sort.Sort(ByParseOrder(cmps))
+	cmps, warnings := benchcmp.Correlate(before, after)
+	if !*force {
+		warnFewMatches(oldPath, newPath, len(cmps), len(before), len(after))
 	}
+
+	if len(warnings) > 0 {
+		fmt.Fprintf(stderr, "benchcmp: WARNING: %d benchmarks ignored:\n", len(warnings))
+		warnList(warnings)
+	}
+	onlyBefore, onlyAfter := benchcmp.Unmatched(before, after)
+	onlyBefore = reportSkipped(newPath, onlyBefore, newLog.Skipped)
+	onlyAfter = reportSkipped(oldPath, onlyAfter, oldLog.Skipped)
+	reportUnmatched(oldPath, onlyBefore)
+	reportUnmatched(newPath, onlyAfter)
+
+	if len(cmps) == 0 {
+		return nil, errors.New("no repeated benchmarks")
+	}
+
+	var selected []benchcmp.BenchCmp
 	for _, cmp := range cmps {
-		if !cmp.Measured(NsOp) {
+		if selects(cmp.Name()) {
+			selected = append(selected, cmp)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, errNoneSelected()
+	}
+	if !*ciMode {
+		warnFewSamples(fewSamples(selected, *minSamples))
+	}
+	return selected, nil
+}
+
+// checkEmpty returns an error naming those of paths whose logs hold no
+// benchmarks, if any do, so that an empty run is told apart from runs
+// with no benchmarks in common.
+func checkEmpty(paths []string, logs []*benchcmp.Log) error {
+	var empty []string
+	for i, log := range logs {
+		if len(log.Benchmarks) == 0 {
+			empty = append(empty, paths[i])
+		}
+	}
+	switch {
+	case len(empty) == 0:
+		return nil
+	case len(empty) == 2 && len(paths) == 2:
+		return fmt.Errorf("no benchmarks in either %s or %s", empty[0], empty[1])
+	case len(empty) == len(paths):
+		return fmt.Errorf("no benchmarks in any of %s", strings.Join(empty, ", "))
+	}
+	return fmt.Errorf("no benchmarks in %s", strings.Join(empty, ", "))
+}
+
+// fuzzyRename renames the benchmarks in before that match those in after
+// only fuzzily, as by benchcmp.FuzzyRenames, reporting each match, listed
+// as by warnList.
+func fuzzyRename(before, after benchcmp.BenchSet) benchcmp.BenchSet {
+	names, warnings := benchcmp.FuzzyRenames(before, after)
+	if len(warnings) > 0 {
+		fmt.Fprintf(stderr, "benchcmp: -fuzzy: %d matches and ambiguities:\n", len(warnings))
+		warnList(warnings)
+	}
+	return benchcmp.Rename(before, names)
+}
+
+// reportSkipped warns about each benchmark selected by -filter among
+// names that was skipped in the file at path, according to skipped,
+// and returns the rest of names. A skipped benchmark is reported by
+// testing.B without its GOMAXPROCS suffix, so either name matches.
+func reportSkipped(path string, names, skipped []string) []string {
+	skip := make(map[string]bool)
+	for _, name := range skipped {
+		skip[name] = true
+	}
+	var rest []string
+	for _, name := range names {
+		base, _ := benchcmp.SplitName(name)
+		if !skip[name] && !skip[base] {
+			rest = append(rest, name)
 			continue
 		}
-		if delta := cmp.DeltaNsOp(); !*changedOnly || delta.Changed() {
-			if !header {
-				fmt.Fprintf(w, "benchmark\told ns/op\tnew ns/op\tdelta\t\n")
-				header = true
+		if selects(name) {
+			fmt.Fprintf(stderr, "benchmark %s skipped in %s\n", name, path)
+		}
+	}
+	return rest
+}
+
+// reportUnmatched lists to standard error the benchmarks selected by
+// -filter among names, which appear only in the file at path.
+func reportUnmatched(path string, names []string) {
+	var selected []string
+	for _, name := range names {
+		if selects(name) {
+			selected = append(selected, name)
+		}
+	}
+	if len(selected) == 0 {
+		return
+	}
+	fmt.Fprintf(stderr, "only in %s:\n", path)
+	warnList(selected)
+}
+
+// warnList writes items to standard error, each on a line of its own
+// indented by a tab. With -max-warnings, only that many are written,
+// followed by a count of the rest, so that files that barely overlap
+// do not bury the comparison.
+func warnList(items []string) {
+	n := len(items)
+	if *maxWarnings > 0 && n > *maxWarnings {
+		n = *maxWarnings
+	}
+	for _, item := range items[:n] {
+		fmt.Fprintf(stderr, "\t%s\n", item)
+	}
+	if n < len(items) {
+		fmt.Fprintf(stderr, "\t... and %d more\n", len(items)-n)
+	}
+}
+
+// closeOutput closes the file named by -o, if any.
+func closeOutput() {
+	if stdout == os.Stdout {
+		return
+	}
+	if err := stdout.Close(); err != nil {
+		fatal(err)
+	}
+}
+
+// output writes cmps to stdout using render. Formats other than text
+// list each benchmark once, so cmps are sorted for them as for the
+// ns/op table, and limited to -top of them.
+func output(render benchcmp.Renderer, cmps []benchcmp.BenchCmp) {
+	if _, text := render.(textRenderer); !text {
+		if *magSort {
+			sort.Sort(ordered(sortSection(cmps).order(cmps)))
+		} else {
+			sort.Sort(ordered(baseOrder(cmps)))
+		}
+		if *top > 0 && len(cmps) > *top {
+			cmps = cmps[:*top]
+		}
+	}
+	if err := render.Render(stdout, cmps); err != nil {
+		fatal(err)
+	}
+}
+
+// compareN compares the benchmarks in each of paths, in order, and
+// returns the comparisons selected by -filter and -exclude, for -ci to
+// check.
+func compareN(paths []string) []benchcmp.BenchCmpN {
+	logs := parseFiles(paths...)
+	if err := checkEmpty(paths, logs); err != nil {
+		fatal("benchcmp: " + err.Error())
+	}
+	sets := make([]benchcmp.BenchSet, len(paths))
+	for i := range paths {
+		sets[i] = logs[i].Benchmarks
+		if i < len(paths)-1 {
+			sets[i] = benchcmp.Rename(sets[i], renames)
+		}
+		if *normalizeTo != "" {
+			var err error
+			if sets[i], err = normalize(paths[i], sets[i], *normalizeTo); err != nil {
+				fatal("benchcmp: " + err.Error())
 			}
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", cmp.Name(), formatNs(cmp.Before.NsOp), formatNs(cmp.After.NsOp), delta.Percent())
 		}
 	}
+	if *fuzzy {
+		last := sets[len(sets)-1]
+		for i := range sets[:len(sets)-1] {
+			sets[i] = fuzzyRename(sets[i], last)
+		}
+	}
+	if !*force {
+		warnConfig(paths, logs)
+	}
+
+	cmps, warnings := benchcmp.CorrelateN(sets)
+	if len(warnings) > 0 {
+		fmt.Fprintf(stderr, "benchcmp: WARNING: %d benchmarks ignored:\n", len(warnings))
+		warnList(warnings)
+	}
 
-	header = false
-	if *magSort {
-		sort.Sort(ByDeltaMbS(cmps))
+	if len(cmps) == 0 {
+		fatal("benchcmp: no repeated benchmarks")
 	}
+
+	var selected []benchcmp.BenchCmpN
 	for _, cmp := range cmps {
-		if !cmp.Measured(MbS) {
+		if selects(cmp.Name()) {
+			selected = append(selected, cmp)
+		}
+	}
+	if len(selected) == 0 {
+		fatal("benchcmp: " + errNoneSelected().Error())
+	}
+	if !*ciMode {
+		warnFewSamples(fewSamplesN(selected, *minSamples))
+	}
+
+	writeTextHeader(stdout)
+	renderTextN(stdout, selected)
+	closeOutput()
+	return selected
+}
+
+// selects reports whether the benchmark with the given name
+// should be compared, according to -filter and -exclude: -filter
+// selects it and -exclude then removes it.
+func selects(name string) bool {
+	if excludeRE != nil && excludeRE.MatchString(name) {
+		return false
+	}
+	return filterRE == nil || filterRE.MatchString(name)
+}
+
+// errNoneSelected returns the error for when selects rejects every
+// benchmark compared, naming the flags responsible.
+func errNoneSelected() error {
+	switch {
+	case excludeRE == nil:
+		return errors.New("no benchmarks match -filter")
+	case filterRE == nil:
+		return errors.New("every benchmark matches -exclude")
+	}
+	return errors.New("no benchmarks match -filter but not -exclude")
+}
+
+// groupByProcs groups cmps by the GOMAXPROCS suffix of their names.
+// It returns the distinct GOMAXPROCS values in increasing order.
+func groupByProcs(cmps []benchcmp.BenchCmp) ([]int, map[int][]benchcmp.BenchCmp) {
+	groups := make(map[int][]benchcmp.BenchCmp)
+	var procs []int
+	for _, cmp := range cmps {
+		_, p := benchcmp.SplitName(cmp.Name())
+		if _, ok := groups[p]; !ok {
+			procs = append(procs, p)
+		}
+		groups[p] = append(groups[p], cmp)
+	}
+	sort.Ints(procs)
+	return procs, groups
+}
+
+// renderers holds the output formats, keyed by -format name.
+// Each renders the BenchCmps it is given in order.
+var renderers = map[string]benchcmp.Renderer{
+	"text":   textRenderer{},
+	"json":   benchcmp.RendererFunc(renderJSON),
+	"csv":    benchcmp.RendererFunc(renderCSV),
+	"github": benchcmp.RendererFunc(renderGitHub),
+	"html":   benchcmp.RendererFunc(renderHTML),
+	"influx": benchcmp.RendererFunc(renderInflux),
+	"junit": benchcmp.RendererFunc(func(w io.Writer, cmps []benchcmp.BenchCmp) error {
+		return renderJUnit(w, cmps, threshold)
+	}),
+	"markdown": benchcmp.RendererFunc(renderMarkdown),
+	"prom":     benchcmp.RendererFunc(renderProm),
+	"stable":   benchcmp.RendererFunc(renderStable),
+	"tsv":      benchcmp.RendererFunc(renderTSV),
+	"yaml":     benchcmp.RendererFunc(renderYAML),
+}
+
+// textRenderer writes comparisons as aligned text tables, one per
+// measurement, grouped by GOMAXPROCS with -split=gomaxprocs and by
+// name prefix with -group=prefix.
+type textRenderer struct{}
+
+func (textRenderer) Render(w io.Writer, cmps []benchcmp.BenchCmp) error {
+	if *group == "prefix" {
+		return renderGroups(w, cmps)
+	}
+	if *split != "gomaxprocs" {
+		return renderText(w, cmps)
+	}
+	procs, groups := groupByProcs(cmps)
+	for i, p := range procs {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "GOMAXPROCS=%d\n\n", p)
+		if err := renderText(w, groups[p]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A section describes the table displayed for one measurement.
+type section struct {
+	flag   int    // Measured flag, or 0 for an extra measurement
+	unit   string // unit as reported by testing.B, e.g. "allocs/op"
+	label  string // short name for column headers, e.g. "allocs"
+	change string // column header for the change
+	higher bool   // whether higher values are better
+
+	value func(*benchcmp.Bench) string
+	delta func(benchcmp.BenchCmp) benchcmp.Delta
+	show  func(benchcmp.Delta) string              // formats the change
+	diff  func(benchcmp.Delta) string              // formats the absolute change, if -abs applies
+	sort  func([]benchcmp.BenchCmp) sort.Interface // magnitude sort order
+}
+
+// sections lists the measurement tables in display order.
+var sections = []section{
+	{
+		flag: benchcmp.NsOp, unit: "ns/op", label: "ns/op", change: "delta",
+		value: func(b *benchcmp.Bench) string { return formatNsOp(b.NsOp) },
+		delta: benchcmp.BenchCmp.DeltaNsOp,
+		show:  benchcmp.Delta.Percent,
+		diff:  func(d benchcmp.Delta) string { return formatDiff(d, formatNsOp) },
+		sort:  func(c []benchcmp.BenchCmp) sort.Interface { return benchcmp.ByDeltaNsOp(c) },
+	},
+	{
+		flag: benchcmp.MbS, unit: "MB/s", label: "MB/s", change: "speedup", higher: true,
+		value: func(b *benchcmp.Bench) string { return b.Format("MB/s") },
+		delta: benchcmp.BenchCmp.DeltaMbS,
+		show:  func(d benchcmp.Delta) string { return d.MultipleN(*multPrec) },
+		sort:  func(c []benchcmp.BenchCmp) sort.Interface { return benchcmp.ByDeltaMbS(c) },
+	},
+	{
+		flag: benchcmp.AllocsOp, unit: "allocs/op", label: "allocs", change: "delta",
+		value: func(b *benchcmp.Bench) string { return b.Format("allocs/op") },
+		delta: benchcmp.BenchCmp.DeltaAllocsOp,
+		show:  benchcmp.Delta.Percent,
+		diff:  func(d benchcmp.Delta) string { return formatDiff(d, formatCount) },
+		sort:  func(c []benchcmp.BenchCmp) sort.Interface { return benchcmp.ByDeltaAllocsOp(c) },
+	},
+	{
+		flag: benchcmp.BOp, unit: "B/op", label: "bytes", change: "delta",
+		value: func(b *benchcmp.Bench) string { return b.Format("B/op") },
+		delta: benchcmp.BenchCmp.DeltaBOp,
+		show:  benchcmp.Delta.Percent,
+		diff:  func(d benchcmp.Delta) string { return formatDiff(d, formatCount) },
+		sort:  func(c []benchcmp.BenchCmp) sort.Interface { return benchcmp.ByDeltaBOp(c) },
+	},
+}
+
+// showDiff reports whether the absolute change is displayed for s.
+func (s section) showDiff() bool {
+	return *absDelta && s.diff != nil
+}
+
+// highVariance is the relative standard deviation, in percent,
+// above which -stddev flags a measurement as unreliable.
+const highVariance = 10
+
+// sampleValue returns the value of the measurement described by s in b.
+// With -stddev, the relative standard deviation of the samples follows,
+// marked with "!" if it is high.
+func (s section) sampleValue(b *benchcmp.Bench) string {
+	v := s.value(b)
+	if !*showStdDev {
+		return v
+	}
+	rsd, ok := b.RelStdDev(s.unit)
+	if !ok {
+		return v
+	}
+	return v + " " + formatStdDev(rsd)
+}
+
+// formatStdDev formats a relative standard deviation, given as a
+// fraction, as a percentage marked with "!" above highVariance.
+func formatStdDev(rsd float64) string {
+	pct := 100 * rsd
+	if pct > highVariance {
+		return fmt.Sprintf("±%.0f%%!", pct)
+	}
+	return fmt.Sprintf("±%.0f%%", pct)
+}
+
+// showMbPercent changes the MB/s section to show a percent change,
+// like the other sections, rather than a speedup.
+func showMbPercent() {
+	for i := range sections {
+		if sections[i].flag == benchcmp.MbS {
+			sections[i].change = "delta"
+			sections[i].show = benchcmp.Delta.Percent
+		}
+	}
+}
+
+// measured reports whether both benchmarks in cmp recorded s,
+// including every measurement s is derived from.
+func (s section) measured(cmp benchcmp.BenchCmp) bool {
+	if s.flag == 0 {
+		return cmp.MeasuredExtra(s.unit)
+	}
+	return cmp.Before.Measured&cmp.After.Measured&s.flag == s.flag
+}
+
+// measuredN reports whether every run of cmp recorded the measurement
+// of s.
+func (s section) measuredN(cmp benchcmp.BenchCmpN) bool {
+	for _, b := range cmp.Benches[1:] {
+		if !s.measured(benchcmp.BenchCmp{Before: cmp.Benches[0], After: b}) {
+			return false
+		}
+	}
+	return true
+}
+
+// extraSection returns a section for the extra measurement with the
+// given unit, displayed with a percent change. Rates (units ending
+// in "/s") are taken to be better when higher; all else when lower.
+// Percentile latencies, such as p99_ns/op, are formatted like ns/op
+// and are always better when lower.
+func extraSection(unit string) section {
+	delta := func(c benchcmp.BenchCmp) benchcmp.Delta { return c.DeltaExtra(unit) }
+	s := section{
+		unit: unit, label: unit, change: "delta", higher: strings.HasSuffix(unit, "/s"),
+		value: func(b *benchcmp.Bench) string { return b.Format(unit) },
+		delta: delta,
+		show:  benchcmp.Delta.Percent,
+		sort:  func(c []benchcmp.BenchCmp) sort.Interface { return benchcmp.ByDelta{Cmps: c, Delta: delta} },
+	}
+	if isPercentile(unit) {
+		s.higher = false
+		s.value = func(b *benchcmp.Bench) string { return formatNsOp(b.Extra[unit]) }
+		s.diff = func(d benchcmp.Delta) string { return formatDiff(d, formatNsOp) }
+	}
+	return s
+}
+
+// percentileRE matches the name of a percentile metric, such as p99
+// or p99.9, at the start of a unit, as in p99_ns/op.
+var percentileRE = regexp.MustCompile(`^p\d+(\.\d+)?([_/-]|$)`)
+
+// isPercentile reports whether unit is that of a percentile latency
+// reported with b.ReportMetric, such as p50-ns/op or p99_ns/op.
+func isPercentile(unit string) bool {
+	return percentileRE.MatchString(unit)
+}
+
+// allSections returns sections followed by a section for each extra
+// measurement recorded by both sides of any of cmps, ordered by unit.
+func allSections(cmps []benchcmp.BenchCmp) []section {
+	seen := make(map[string]bool)
+	var extra []string
+	for _, cmp := range cmps {
+		for unit := range cmp.Before.Extra {
+			if !seen[unit] && cmp.MeasuredExtra(unit) {
+				seen[unit] = true
+				extra = append(extra, unit)
+			}
+		}
+	}
+	sort.Strings(extra)
+	all := append([]section(nil), sections...)
+	for _, unit := range extra {
+		all = append(all, extraSection(unit))
+	}
+	return all
+}
+
+// totalTime returns the change in the total time taken by each side of
+// c, the number of iterations times ns/op. Unlike ns/op, it reflects
+// the number of iterations that testing.B chose to run.
+func totalTime(c benchcmp.BenchCmp) benchcmp.Delta {
+	return benchcmp.Delta{
+		Before: float64(c.Before.N) * c.Before.NsOp,
+		After:  float64(c.After.N) * c.After.NsOp,
+	}
+}
+
+// timeSection describes the table of total time shown with -time.
+var timeSection = section{
+	flag: benchcmp.NsOp, unit: "time", label: "time", change: "delta",
+	value: func(b *benchcmp.Bench) string { return formatTime(float64(b.N) * b.NsOp) },
+	delta: totalTime,
+	show:  benchcmp.Delta.Percent,
+	diff:  func(d benchcmp.Delta) string { return formatDiff(d, formatTime) },
+	sort:  func(c []benchcmp.BenchCmp) sort.Interface { return benchcmp.ByDelta{Cmps: c, Delta: totalTime} },
+}
+
+// allocRateOf returns the bytes allocated per second by b,
+// or 0 if b took no time.
+func allocRateOf(b *benchcmp.Bench) float64 {
+	if b.NsOp == 0 {
+		return 0
+	}
+	return float64(b.BOp) * 1e9 / b.NsOp
+}
+
+// allocRateDelta returns the change in the bytes allocated per second
+// by each side of c.
+func allocRateDelta(c benchcmp.BenchCmp) benchcmp.Delta {
+	return benchcmp.Delta{Before: allocRateOf(c.Before), After: allocRateOf(c.After)}
+}
+
+// allocRateSection describes the table of bytes allocated per second
+// shown with -allocrate, for benchmarks that measured both B/op and ns/op.
+var allocRateSection = section{
+	flag: benchcmp.NsOp | benchcmp.BOp, unit: "B/s", label: "allocrate", change: "delta",
+	value: func(b *benchcmp.Bench) string { return formatByteRate(allocRateOf(b)) },
+	delta: allocRateDelta,
+	show:  benchcmp.Delta.Percent,
+	diff:  func(d benchcmp.Delta) string { return formatDiff(d, formatByteRate) },
+	sort:  func(c []benchcmp.BenchCmp) sort.Interface { return benchcmp.ByDelta{Cmps: c, Delta: allocRateDelta} },
+}
+
+// allocsRateOf returns the allocations made per second by b,
+// or 0 if b took no time.
+func allocsRateOf(b *benchcmp.Bench) float64 {
+	if b.NsOp == 0 {
+		return 0
+	}
+	return float64(b.AllocsOp) * 1e9 / b.NsOp
+}
+
+// allocsRateDelta returns the change in the allocations made per
+// second by each side of c.
+func allocsRateDelta(c benchcmp.BenchCmp) benchcmp.Delta {
+	return benchcmp.Delta{Before: allocsRateOf(c.Before), After: allocsRateOf(c.After)}
+}
+
+// allocsRateSection describes the table of allocations per second shown
+// with -allocspersec, for benchmarks that measured both allocs/op and
+// ns/op.
+var allocsRateSection = section{
+	flag: benchcmp.NsOp | benchcmp.AllocsOp, unit: "allocs/s", label: "allocs/s", change: "delta",
+	value: func(b *benchcmp.Bench) string { return formatAllocsRate(allocsRateOf(b)) },
+	delta: allocsRateDelta,
+	show:  benchcmp.Delta.Percent,
+	diff:  func(d benchcmp.Delta) string { return formatDiff(d, formatAllocsRate) },
+	sort:  func(c []benchcmp.BenchCmp) sort.Interface { return benchcmp.ByDelta{Cmps: c, Delta: allocsRateDelta} },
+}
+
+// tableSections returns the sections displayed as tables for cmps:
+// allSections, followed by timeSection with -time, allocRateSection
+// with -allocrate, and allocsRateSection with -allocspersec, limited
+// to those selected by -metric and measured by at least -minreport
+// benchmarks.
+func tableSections(cmps []benchcmp.BenchCmp) []section {
+	all := allSections(cmps)
+	if *showTime {
+		all = append(all, timeSection)
+	}
+	if *allocRate {
+		all = append(all, allocRateSection)
+	}
+	if *allocsRate {
+		all = append(all, allocsRateSection)
+	}
+	var shown []section
+	for _, s := range selectSections(all) {
+		n := 0
+		for _, cmp := range cmps {
+			if s.measured(cmp) {
+				n++
+			}
+		}
+		if n >= *minReport {
+			shown = append(shown, s)
+		}
+	}
+	return shown
+}
+
+// selectSections returns the sections in all selected by -metric.
+func selectSections(all []section) []section {
+	if metrics == nil {
+		return all
+	}
+	var sel []section
+	for _, s := range all {
+		if metrics[s.label] || metrics[s.unit] {
+			sel = append(sel, s)
+		}
+	}
+	return sel
+}
+
+// knownSections returns sections followed by timeSection
+// and the sections of the allocation rates.
+func knownSections() []section {
+	return append(append([]section(nil), sections...), timeSection, allocRateSection, allocsRateSection)
+}
+
+// metricNames returns the names that -metric accepts for the
+// measurements that are always available.
+func metricNames() []string {
+	var names []string
+	for _, s := range knownSections() {
+		names = append(names, s.label)
+	}
+	return names
+}
+
+// validMetric reports whether name may be selected by -metric: the label
+// or unit of a known measurement, or what may be the unit of a custom one.
+func validMetric(name string) bool {
+	for _, s := range knownSections() {
+		if name == s.label || name == s.unit {
+			return true
+		}
+	}
+	return strings.Contains(name, "/")
+}
+
+// renderText writes cmps to out as a set of aligned tables, one per
+// measurement.
+func renderText(out io.Writer, cmps []benchcmp.BenchCmp) error {
+	w := newTableWriter(out, *minWidth, *padding)
+
+	if !*magSort {
+		sort.Sort(ordered(baseOrder(cmps)))
+	} else if *sortKey != "" {
+		sort.Sort(ordered(sortSection(cmps).order(cmps)))
+	}
+	var shown []section // tables displayed so far
+	for _, s := range tableSections(cmps) {
+		rows := s.rows(cmps)
+		if len(rows) == 0 {
 			continue
 		}
-		if delta := cmp.DeltaMbS(); !*changedOnly || delta.Changed() {
-			if !header {
-				fmt.Fprintf(w, "\nbenchmark\told MB/s\tnew MB/s\tspeedup\t\n")
-				header = true
+		if len(shown) > 0 {
+			fmt.Fprint(w, "\n")
+		}
+		shown = append(shown, s)
+		sampled := hasPValues(cmps, s)
+		dc := s.changeColumn()
+		header := s.header(sampled)
+		header[dc] = paint(header[dc], 0)
+		if *showBars {
+			header = append(header, "")
+		}
+		writeRow(w, header)
+		scale := s.barScale(rows)
+		for _, cmp := range rows {
+			cells := s.cells(cmp, sampled)
+			worse := s.worsening(s.delta(cmp))
+			if cells[dc] == "~" {
+				cells[dc] = paint(cells[dc], 0)
+				worse = 0
+			} else {
+				cells[dc] = paint(cells[dc], sign(worse))
 			}
-			fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%s\t\n", cmp.Name(), cmp.Before.MbS, cmp.After.MbS, delta.Multiple())
+			if *showBars {
+				cells = append(cells, bar(worse, scale))
+			}
+			writeRow(w, cells)
+		}
+		if *showGeoMean {
+			writeGeoMean(w, s, cmps, dc-1)
 		}
 	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if *showCounts && len(shown) > 0 {
+		fmt.Fprintln(out)
+		renderCounts(out, shown, cmps)
+	}
+	if _, n := totalBytes(cmps); *totalBOp && n > 0 {
+		fmt.Fprintln(out)
+		renderTotalBytes(out, cmps)
+	}
+	return nil
+}
 
-	header = false
-	if *magSort {
-		sort.Sort(ByDeltaAllocsOp(cmps))
+// rows returns the benchmarks in cmps to display in the table for s,
+// in display order: those that measured s, only those that changed
+// with -changed (and their neighbors with -context), and at most -top
+// of them.
+func (s section) rows(cmps []benchcmp.BenchCmp) []benchcmp.BenchCmp {
+	shown := changedOnly.showsAround(s, cmps)
+	if *magSort && *sortKey == "" {
+		sort.Sort(ordered(s.order(cmps)))
 	}
+	var rows []benchcmp.BenchCmp
 	for _, cmp := range cmps {
-		if !cmp.Measured(AllocsOp) {
-			continue
+		if *top > 0 && len(rows) == *top {
+			break
+		}
+		if shown[cmp.Before] {
+			rows = append(rows, cmp)
+		}
+	}
+	return rows
+}
+
+// order returns the sort of cmps used for the table of s with -mag, or
+// with -sort=delta, which puts the worst regression of s first. Ties
+// in the change are ordered by tieBreak, and changes are limited to
+// -max-delta.
+func (s section) order(cmps []benchcmp.BenchCmp) sort.Interface {
+	tie := tieBreak()
+	if !signedSort && tie == nil && *maxDelta == 0 {
+		return s.sort(cmps)
+	}
+	delta := s.delta
+	if *maxDelta > 0 {
+		delta = func(c benchcmp.BenchCmp) benchcmp.Delta { return clampDelta(s.delta(c), *maxDelta) }
+	}
+	if !signedSort {
+		return benchcmp.ByDelta{Cmps: cmps, Delta: delta, Tie: tie}
+	}
+	if s.higher {
+		// A decrease is a regression; sort by the inverse change.
+		clamped := delta
+		delta = func(c benchcmp.BenchCmp) benchcmp.Delta {
+			d := clamped(c)
+			return benchcmp.Delta{Before: d.After, After: d.Before}
 		}
-		if delta := cmp.DeltaAllocsOp(); !*changedOnly || delta.Changed() {
-			if !header {
-				fmt.Fprintf(w, "\nbenchmark\told allocs\tnew allocs\tdelta\t\n")
-				header = true
+	}
+	return benchcmp.BySignedDelta{Cmps: cmps, Delta: delta, Tie: tie}
+}
+
+// changeColumn returns the index of the change column in the table for s.
+func (s section) changeColumn() int {
+	if s.showDiff() {
+		return 4
+	}
+	return 3
+}
+
+// header returns the column headers of the table for s. If sampled,
+// the table has a column for the p-value of each change, followed by
+// the ratio of each change with -ratio.
+func (s section) header(sampled bool) []string {
+	cells := []string{"benchmark", "old " + s.label, "new " + s.label}
+	if s.showDiff() {
+		cells = append(cells, "abs delta")
+	}
+	cells = append(cells, s.change)
+	if sampled {
+		cells = append(cells, pHeader())
+	}
+	if s.showRatio() {
+		cells = append(cells, "ratio")
+	}
+	if s.showOps() {
+		cells = append(cells, "old ops/s", "new ops/s", "ops/s delta")
+	}
+	return cells
+}
+
+// cells returns the row for cmp in the table for s. A change that is
+// not statistically significant is shown as "~".
+func (s section) cells(cmp benchcmp.BenchCmp, sampled bool) []string {
+	delta := s.delta(cmp)
+	cells := []string{cmp.Name(), s.sampleValue(cmp.Before), s.sampleValue(cmp.After)}
+	if s.showDiff() {
+		cells = append(cells, s.diff(delta))
+	}
+	cells = append(cells, s.showClamped(delta))
+	if sampled {
+		cells = append(cells, "")
+		if p, ok := pValue(cmp, s.unit); ok {
+			if p > alpha {
+				cells[len(cells)-2] = "~"
 			}
-			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t\n", cmp.Name(), cmp.Before.AllocsOp, cmp.After.AllocsOp, delta.Percent())
+			cells[len(cells)-1] = fmt.Sprintf("%.3f", p)
 		}
 	}
+	if s.showRatio() {
+		r := formatRatio(delta)
+		if cells[s.changeColumn()] == "~" {
+			r = "~"
+		}
+		cells = append(cells, r)
+	}
+	if s.showOps() {
+		ops := opsDelta(cmp)
+		if cells[s.changeColumn()] == "~" {
+			ops = "~"
+		}
+		cells = append(cells, formatOps(cmp.Before.NsOp), formatOps(cmp.After.NsOp), ops)
+	}
+	return cells
+}
+
+// showOps reports whether operations per second are displayed for s.
+func (s section) showOps() bool {
+	return *opsPerSec && s.flag == benchcmp.NsOp
+}
 
-	header = false
-	if *magSort {
-		sort.Sort(ByDeltaBOp(cmps))
+// formatOps formats the operations per second of a benchmark that
+// takes ns nanoseconds per operation, or "-" if ns is zero.
+func formatOps(ns float64) string {
+	if ns == 0 {
+		return "-"
 	}
+	return benchcmp.FormatNs(1e9 / ns)
+}
+
+// opsDelta formats the percent change in operations per second in cmp,
+// which is positive when cmp got faster. It is "?" if either side took
+// zero ns/op, which has no meaningful rate.
+func opsDelta(cmp benchcmp.BenchCmp) string {
+	if cmp.Before.NsOp == 0 || cmp.After.NsOp == 0 {
+		return "?"
+	}
+	return benchcmp.Delta{Before: 1e9 / cmp.Before.NsOp, After: 1e9 / cmp.After.NsOp}.Percent()
+}
+
+// pValue returns the p-value of the significance test chosen by -test
+// on the samples of the measurement with the given unit in cmp. It
+// reports false if either side has too few samples.
+func pValue(cmp benchcmp.BenchCmp, unit string) (float64, bool) {
+	return cmp.PValueFunc(unit, tests[*sigTest])
+}
+
+// alpha is the significance level below which a change in the samples
+// of a measurement is considered real rather than noise: 1 - -confidence.
+var alpha = 0.05
+
+// defaultConfidence is the default -confidence, which the
+// p-value column header leaves unstated.
+const defaultConfidence = 0.95
+
+// pHeader returns the header of the p-value column,
+// naming the confidence level if it is not the default.
+func pHeader() string {
+	if *confidence == defaultConfidence {
+		return "p"
+	}
+	return fmt.Sprintf("p (%.4g%% conf.)", 100**confidence)
+}
+
+// hasPValues reports whether any of cmps has enough samples to test
+// the measurement described by s for a significant change.
+func hasPValues(cmps []benchcmp.BenchCmp, s section) bool {
 	for _, cmp := range cmps {
-		if !cmp.Measured(BOp) {
+		if !s.measured(cmp) {
 			continue
 		}
-		if delta := cmp.DeltaBOp(); !*changedOnly || delta.Changed() {
-			if !header {
-				fmt.Fprintf(w, "\nbenchmark\told bytes\tnew bytes\tdelta\t\n")
-				header = true
+		if _, ok := pValue(cmp, s.unit); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// writeRow writes one row of tab-terminated cells to w.
+func writeRow(w io.Writer, cells []string) {
+	fmt.Fprintf(w, "%s\t\n", strings.Join(cells, "\t"))
+}
+
+// writeGeoMean writes a summary row for section s holding the geometric
+// mean of the changes in cmps. The row is padded to cols value columns.
+func writeGeoMean(w io.Writer, s section, cmps []benchcmp.BenchCmp, cols int) {
+	d, n := s.geoMean(cmps)
+	if n == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s\t%s%s\t\n", geoMeanLabel(n), strings.Repeat("\t", cols), paint(s.show(d), sign(s.worsening(d))))
+}
+
+// geoMeanLabel returns the name of the summary row of a table holding
+// the geometric mean of n changes.
+func geoMeanLabel(n int) string {
+	if *weighted {
+		return fmt.Sprintf("[weighted geomean of %d]", n)
+	}
+	return fmt.Sprintf("[geomean of %d]", n)
+}
+
+// geoMean returns the geometric mean of the changes in cmps of the
+// measurement described by s, as a Delta from 1, and the number of
+// benchmarks included. With -weighted, each change is weighted by the
+// total time of the old run of its benchmark, as by totalTime.
+func (s section) geoMean(cmps []benchcmp.BenchCmp) (benchcmp.Delta, int) {
+	measure := func(c benchcmp.BenchCmp) (benchcmp.Delta, bool) { return s.delta(c), s.measured(c) }
+	mean, n := benchcmp.GeoMeanFunc(cmps, measure)
+	if *weighted {
+		mean, n = benchcmp.WeightedGeoMeanFunc(cmps, measure, func(c benchcmp.BenchCmp) float64 { return totalTime(c).Before })
+	}
+	return benchcmp.Delta{Before: 1, After: mean}, n
+}
+
+// renderTextN writes cmps to out like renderText, with one column
+// for each run. The change shown is from the first run to the last.
+func renderTextN(out io.Writer, cmps []benchcmp.BenchCmpN) {
+	w := newTableWriter(out, *minWidth, *padding)
+	defer w.Flush()
+
+	if !*magSort {
+		sortN(cmps, baseOrder)
+	} else if *sortKey != "" {
+		sortN(cmps, func(c []benchcmp.BenchCmp) sort.Interface { return sortSection(c).order(c) })
+	}
+	spans := make([]benchcmp.BenchCmp, len(cmps))
+	for i, cmp := range cmps {
+		spans[i] = cmp.Span()
+	}
+	var written bool // Has any table been written yet?
+	for _, s := range selectSections(allSections(spans)) {
+		n := 0
+		for _, cmp := range cmps {
+			if s.measuredN(cmp) {
+				n++
+			}
+		}
+		if n < *minReport {
+			continue
+		}
+		var header bool // Has the header has been displayed yet for this block?
+		var shown int   // How many benchmarks have been displayed in this block?
+		around := changedOnly.showsAround(s, spans)
+		if *magSort && *sortKey == "" {
+			sortN(cmps, s.order)
+		}
+		for _, cmp := range cmps {
+			if *top > 0 && shown == *top {
+				break
+			}
+			if !s.measuredN(cmp) {
+				continue
+			}
+			if delta := s.delta(cmp.Span()); around[cmp.Benches[0]] {
+				shown++
+				if !header {
+					if written {
+						fmt.Fprint(w, "\n")
+					}
+					written = true
+					fmt.Fprint(w, "benchmark\t")
+					for n := range cmp.Benches {
+						fmt.Fprintf(w, "%s #%d\t", s.label, n+1)
+					}
+					if s.showDiff() {
+						fmt.Fprint(w, "abs delta\t")
+					}
+					fmt.Fprintf(w, "%s\t\n", paint(s.change, 0))
+					header = true
+				}
+				fmt.Fprintf(w, "%s\t", cmp.Name())
+				for _, b := range cmp.Benches {
+					fmt.Fprintf(w, "%s\t", s.sampleValue(b))
+				}
+				if s.showDiff() {
+					fmt.Fprintf(w, "%s\t", s.diff(delta))
+				}
+				fmt.Fprintf(w, "%s\t\n", paint(s.showClamped(delta), sign(s.worsening(delta))))
 			}
-			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t\n", cmp.Name(), cmp.Before.BOp, cmp.After.BOp, cmp.DeltaBOp().Percent())
 		}
+		if header && *showGeoMean {
+			cols := len(cmps[0].Benches)
+			if s.showDiff() {
+				cols++
+			}
+			writeGeoMean(w, s, spans, cols)
+		}
+	}
+}
+
+// baseOrder returns the order in which to show cmps when not sorting
+// by magnitude: by name with -sort=name, and in parse order otherwise.
+func baseOrder(cmps []benchcmp.BenchCmp) sort.Interface {
+	if *sortBy == "name" {
+		return benchcmp.ByName(cmps)
 	}
+	return benchcmp.ByParseOrder(cmps)
+}
+
+// sortN sorts cmps by the order that by gives to the span of each.
+func sortN(cmps []benchcmp.BenchCmpN, by func([]benchcmp.BenchCmp) sort.Interface) {
+	spans := make([]benchcmp.BenchCmp, len(cmps))
+	for i, cmp := range cmps {
+		spans[i] = cmp.Span()
+	}
+	sort.Sort(ordered(byCmpN{by(spans), cmps}))
+}
+
+// ordered returns x, or x in reverse with -reverse.
+func ordered(x sort.Interface) sort.Interface {
+	if *reverse {
+		return sort.Reverse(x)
+	}
+	return x
+}
+
+// byCmpN sorts BenchCmpNs in step with the sort of their spans.
+type byCmpN struct {
+	sort.Interface // sorts the spans of cmps
+	cmps           []benchcmp.BenchCmpN
+}
+
+func (x byCmpN) Swap(i, j int) {
+	x.Interface.Swap(i, j)
+	x.cmps[i], x.cmps[j] = x.cmps[j], x.cmps[i]
 }
 
 func fatal(msg interface{}) {
@@ -135,27 +1556,307 @@ func fatal(msg interface{}) {
 	os.Exit(1)
 }
 
-func parseFile(path string) BenchSet {
-	f, err := os.Open(path)
+// parseFile parses the benchmarks in the named file, as parseFiles does.
+func parseFile(path string) *benchcmp.Log {
+	return parseFiles(path)[0]
+}
+
+// parseFiles parses the benchmarks in each of the named files,
+// or in standard input for a path of "-". A path listing several
+// files separated by commas, as one for each package of a run,
+// parses the benchmarks of all of them, warning of any benchmark
+// found in more than one. Repeated runs of a benchmark are merged
+// as chosen by -aggregate.
+//
+// Every file is read before any warning is printed, and if any
+// cannot be read, or with -strict has lines that would be skipped
+// with a warning, the errors of all of them are reported together
+// before exiting, so that one run reveals every broken file.
+func parseFiles(paths ...string) []*benchcmp.Log {
+	logs, err := readLogs(paths...)
 	if err != nil {
 		fatal(err)
 	}
-	bb, err := ParseBenchSet(f)
+	return logs
+}
+
+// readLogs is like parseFiles, but returns the errors of the files
+// that cannot be read, or with -strict are not accepted, as one error
+// of a line each rather than exiting.
+func readLogs(paths ...string) ([]*benchcmp.Log, error) {
+	lists := make([][]string, len(paths))
+	files := make([][]*benchcmp.Log, len(paths))
+	var errs []string
+	for i, path := range paths {
+		lists[i] = splitPaths(path)
+		for _, p := range lists[i] {
+			log, err := readFile(p)
+			if err != nil {
+				errs = append(errs, "benchcmp: "+err.Error())
+				continue
+			}
+			if *strict {
+				errs = append(errs, strictErrors(p, log)...)
+			}
+			files[i] = append(files[i], log)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+	logs := make([]*benchcmp.Log, len(paths))
+	for i := range paths {
+		logs[i] = mergeFiles(lists[i], files[i])
+	}
+	return logs, nil
+}
+
+// mergeFiles warns of any malformed or misplaced results in logs, the
+// benchmarks read from each of paths, and of any benchmark found in
+// more than one of them, and returns their merged benchmarks, trimmed
+// by -trim and with repeated runs merged as chosen by -aggregate.
+func mergeFiles(paths []string, logs []*benchcmp.Log) *benchcmp.Log {
+	seen := make(map[string]string) // path of the first file with each benchmark
+	for i, p := range paths {
+		warnLog(p, logs[i])
+		var names []string
+		for name := range logs[i].Benchmarks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if first, ok := seen[name]; ok {
+				fmt.Fprintf(stderr, "benchcmp: %s is in both %s and %s; merging its runs\n", name, first, p)
+				continue
+			}
+			seen[name] = p
+		}
+	}
+	log := logs[0]
+	if len(logs) > 1 {
+		log = benchcmp.MergeLogs(logs...)
+	}
+	if *trim > 0 {
+		log.Benchmarks = trimRuns(log.Benchmarks, *trim, *trimFast)
+	}
+	log.Benchmarks = benchcmp.MergeSamplesFunc(log.Benchmarks, aggregators[*aggregate])
+	return log
+}
+
+// splitPaths returns the files listed, separated by commas, in path,
+// unless a file of that name exists.
+func splitPaths(path string) []string {
+	if !strings.Contains(path, ",") {
+		return []string{path}
+	}
+	if _, err := os.Stat(path); err == nil {
+		return []string{path}
+	}
+	return strings.Split(path, ",")
+}
+
+// stdinTwice records that "-" names more than one file, which then
+// each read standard input as buffered in stdinData by readStdin.
+// Otherwise standard input is parsed as it is read, like any file.
+var (
+	stdinTwice bool
+	stdinData  []byte
+	stdinRead  bool
+)
+
+// readStdin returns the whole of standard input, reading it on the
+// first call only, so that "-" given for both files compares a log
+// with itself rather than with nothing.
+func readStdin() ([]byte, error) {
+	if !stdinRead {
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		stdinData, stdinRead = data, true
+	}
+	return stdinData, nil
+}
+
+// readFile parses the benchmarks in the named file, or in standard
+// input if path is "-". Input compressed with gzip is decompressed
+// first and stripped of any -prefix-strip prefixes. Then input in the
+// form written by -format=json is read by parseJSON, that written by
+// go test -json by parseTestJSON, and that of benchstat by
+// parseBenchstat.
+func readFile(path string) (*benchcmp.Log, error) {
+	var r io.Reader = os.Stdin
+	if path == "-" && stdinTwice {
+		data, err := readStdin()
+		if err != nil {
+			return nil, fmt.Errorf("reading standard input: %v", err)
+		}
+		r = bytes.NewReader(data)
+	} else if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	r, err := decompress(r)
 	if err != nil {
-		fatal(err)
+		return nil, fmt.Errorf("decompressing %s: %v", path, err)
+	}
+	if prefixRE != nil {
+		r = stripPrefixes(r, prefixRE)
 	}
-	return bb
+	br := bufio.NewReader(r)
+	if isJSON(br) {
+		bb, err := parseJSON(br)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %v", path, err)
+		}
+		return &benchcmp.Log{Benchmarks: bb}, nil
+	}
+	if isBenchstat(br) {
+		bb, err := parseBenchstat(br)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %v", path, err)
+		}
+		return &benchcmp.Log{Benchmarks: bb}, nil
+	}
+	var log *benchcmp.Log
+	if isTestJSON(br) {
+		log, err = parseTestJSON(br)
+	} else {
+		log, err = benchcmp.ParseLog(br)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %v", path, err)
+	}
+	return log, nil
 }
 
-// formatNs formats ns measurements to expose a useful amount of
-// precision. It mirrors the ns precision logic of testing.B.
-func formatNs(ns float64) string {
-	prec := 0
+// warnLog warns of any malformed, negative, or misplaced results in
+// log, the benchmarks read from the named file.
+func warnLog(path string, log *benchcmp.Log) {
+	if len(log.Malformed) > 0 {
+		fmt.Fprintf(stderr, "benchcmp: %s: skipped %d malformed lines\n", path, len(log.Malformed))
+		warnList(lineList(log.Malformed))
+	}
+	if len(log.Negative) > 0 {
+		fmt.Fprintf(stderr, "benchcmp: %s: skipped %d lines with negative measurements\n", path, len(log.Negative))
+		warnList(lineList(log.Negative))
+	}
+	if len(log.Duplicates) > 0 {
+		fmt.Fprintf(stderr, "benchcmp: %s: %d benchmarks ran again after other benchmarks; merging their runs (was the output appended twice?)\n", path, len(log.Duplicates))
+		warnList(log.Duplicates)
+	}
+}
+
+// lineList returns each of lines as a string, for warnList.
+func lineList(lines []benchcmp.Malformed) []string {
+	list := make([]string, len(lines))
+	for i, m := range lines {
+		list[i] = m.String()
+	}
+	return list
+}
+
+// strictErrors returns an error message for each problem in log, the
+// benchmarks read from the named file, that warnLog would warn of and
+// -strict refuses to accept: lines that are malformed or that report
+// a negative ns/op or MB/s, and benchmarks that ran again after others.
+// Benchmarks skipped by testing.B.Skip are not problems.
+func strictErrors(path string, log *benchcmp.Log) []string {
+	var errs []string
+	for _, m := range log.Malformed {
+		errs = append(errs, fmt.Sprintf("benchcmp: %s: malformed %s", path, m))
+	}
+	for _, m := range log.Negative {
+		errs = append(errs, fmt.Sprintf("benchcmp: %s: negative measurement at %s", path, m))
+	}
+	for _, name := range log.Duplicates {
+		errs = append(errs, fmt.Sprintf("benchcmp: %s: %s ran again after other benchmarks", path, name))
+	}
+	return errs
+}
+
+// gzipMagic is the header that begins every gzip stream.
+const gzipMagic = "\x1f\x8b"
+
+// decompress returns a reader of the contents of r, which are
+// decompressed if they begin with the gzip header.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(gzipMagic))
+	if err != nil || string(magic) != gzipMagic {
+		// Too short to be gzip, or not gzip; any read error
+		// will be reported again by the parser.
+		return br, nil
+	}
+	return gzip.NewReader(br)
+}
+
+// formatDiff formats the signed difference After - Before of d,
+// using format for its magnitude.
+func formatDiff(d benchcmp.Delta, format func(float64) string) string {
+	diff := d.After - d.Before
+	if diff < 0 {
+		return "-" + format(-diff)
+	}
+	return "+" + format(diff)
+}
+
+// formatCount formats a count of allocations or bytes.
+func formatCount(n float64) string {
+	return strconv.FormatFloat(n, 'f', 0, 64)
+}
+
+// formatTime formats a duration of ns nanoseconds
+// with a unit suited to its magnitude.
+func formatTime(ns float64) string {
 	switch {
-	case ns < 10:
-		prec = 2
-	case ns < 100:
-		prec = 1
+	case ns >= 1e9:
+		return fmt.Sprintf("%.2fs", ns/1e9)
+	case ns >= 1e6:
+		return fmt.Sprintf("%.2fms", ns/1e6)
+	case ns >= 1e3:
+		return fmt.Sprintf("%.2fµs", ns/1e3)
+	}
+	return fmt.Sprintf("%.0fns", ns)
+}
+
+// formatByteRate formats a rate of b bytes per second
+// with a unit suited to its magnitude.
+func formatByteRate(b float64) string {
+	switch {
+	case b >= 1e9:
+		return fmt.Sprintf("%.2fGB/s", b/1e9)
+	case b >= 1e6:
+		return fmt.Sprintf("%.2fMB/s", b/1e6)
+	case b >= 1e3:
+		return fmt.Sprintf("%.2fkB/s", b/1e3)
+	}
+	return fmt.Sprintf("%.0fB/s", b)
+}
+
+// formatAllocsRate formats a rate of a allocations per second
+// with a unit suited to its magnitude, as formatByteRate does.
+func formatAllocsRate(a float64) string {
+	switch {
+	case a >= 1e9:
+		return fmt.Sprintf("%.2fG/s", a/1e9)
+	case a >= 1e6:
+		return fmt.Sprintf("%.2fM/s", a/1e6)
+	case a >= 1e3:
+		return fmt.Sprintf("%.2fk/s", a/1e3)
+	}
+	return fmt.Sprintf("%.0f/s", a)
+}
+
+// formatNsOp formats an ns/op measurement with the number of decimal
+// places set by -precision or, by default, as benchcmp.FormatNs does.
+func formatNsOp(ns float64) string {
+	if *precision < 0 {
+		return benchcmp.FormatNs(ns)
 	}
-	return strconv.FormatFloat(ns, 'f', prec, 64)
+	return strconv.FormatFloat(ns, 'f', *precision, 64)
 }
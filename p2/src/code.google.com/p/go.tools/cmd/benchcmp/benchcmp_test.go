@@ -0,0 +1,900 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestDecompress(t *testing.T) {
+	const text = "BenchmarkEncrypt\t100000000\t19.6 ns/op\n"
+	var zipped bytes.Buffer
+	zw := gzip.NewWriter(&zipped)
+	zw.Write([]byte(text))
+	zw.Close()
+
+	cases := []struct {
+		name string
+		in   []byte
+	}{
+		{name: "plain", in: []byte(text)},
+		{name: "gzip", in: zipped.Bytes()},
+		{name: "empty", in: nil},
+	}
+	for _, tt := range cases {
+		r, err := decompress(bytes.NewReader(tt.in))
+		if err != nil {
+			t.Errorf("%s: decompress failed: %v", tt.name, err)
+			continue
+		}
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("%s: reading failed: %v", tt.name, err)
+			continue
+		}
+		want := text
+		if tt.in == nil {
+			want = ""
+		}
+		if have := string(b); want != have {
+			t.Errorf("%s: want %q have %q", tt.name, want, have)
+		}
+	}
+
+	// A corrupt gzip stream is an error.
+	r, err := decompress(strings.NewReader(gzipMagic + "garbage"))
+	if err == nil {
+		_, err = ioutil.ReadAll(r)
+	}
+	if err == nil {
+		t.Errorf("corrupt gzip: want error, have none")
+	}
+}
+
+func TestFormatDiff(t *testing.T) {
+	cases := []struct {
+		before, after float64
+		format        func(float64) string
+		want          string
+	}{
+		{before: 2, after: 3, format: benchcmp.FormatNs, want: "+1.00"},
+		{before: 200e6, after: 300e6, format: benchcmp.FormatNs, want: "+100000000"},
+		{before: 19.6, after: 17.6, format: benchcmp.FormatNs, want: "-2.00"},
+		{before: 150, after: 100, format: benchcmp.FormatNs, want: "-50.0"},
+		{before: 5, after: 5, format: formatCount, want: "+0"},
+		{before: 8, after: 3, format: formatCount, want: "-5"},
+	}
+	for _, tt := range cases {
+		d := benchcmp.Delta{Before: tt.before, After: tt.after}
+		if have := formatDiff(d, tt.format); tt.want != have {
+			t.Errorf("formatDiff(%s): want %q have %q", d, tt.want, have)
+		}
+	}
+}
+
+func TestCellsMatchFormatRow(t *testing.T) {
+	// With no flags, each row of a table is as the library formats it.
+	m := benchcmp.NsOp | benchcmp.MbS | benchcmp.BOp | benchcmp.AllocsOp
+	cmp := benchcmp.BenchCmp{
+		Before: &benchcmp.Bench{Name: "BenchmarkA", NsOp: 0.4567, MbS: 10, BOp: 1 << 40, AllocsOp: 0, Measured: m, Extra: map[string]float64{"items/op": 0.25}},
+		After:  &benchcmp.Bench{Name: "BenchmarkA", NsOp: 1234.5, MbS: 12.5, BOp: 1<<40 + 1, AllocsOp: 7, Measured: m, Extra: map[string]float64{"items/op": 3}},
+	}
+	for _, s := range allSections([]benchcmp.BenchCmp{cmp}) {
+		if want, have := benchcmp.Header(s.unit), strings.Join(s.header(false), "\t"); want != have {
+			t.Errorf("%s header: want %q have %q", s.unit, want, have)
+		}
+		if want, have := cmp.FormatRow(s.unit), strings.Join(s.cells(cmp, false), "\t"); want != have {
+			t.Errorf("%s cells: want %q have %q", s.unit, want, have)
+		}
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	defer func(saved int) { *precision = saved }(*precision)
+
+	for _, tt := range []struct {
+		prec int
+		ns   float64
+		want string
+	}{
+		{-1, 0.4567, "0.46"},
+		{-1, 517, "517"},
+		{4, 0.4567, "0.4567"},
+		{0, 19.6, "20"},
+		{1, 517, "517.0"},
+	} {
+		*precision = tt.prec
+		if have := formatNsOp(tt.ns); have != tt.want {
+			t.Errorf("-precision=%d: formatNsOp(%v): want %q have %q", tt.prec, tt.ns, tt.want, have)
+		}
+	}
+	*precision = 3
+	if have := formatOps(1e6); have != "1000" {
+		t.Errorf("-precision=3: formatOps(1e6): want %q have %q", "1000", have)
+	}
+}
+
+func TestMultPrecision(t *testing.T) {
+	defer func(saved int) { *multPrec = saved }(*multPrec)
+
+	var mb section
+	for _, s := range sections {
+		if s.flag == benchcmp.MbS {
+			mb = s
+		}
+	}
+	for _, tt := range []struct {
+		prec          int
+		before, after float64
+		want          string
+	}{
+		{2, 100, 104, "1.04x"},
+		{2, 100, 98, "0.98x"},
+		{2, 100, 350, "3.50x"},
+		{3, 100, 104.3, "1.043x"},
+		{0, 100, 35000, "350x"},
+	} {
+		*multPrec = tt.prec
+		if have := mb.show(benchcmp.Delta{Before: tt.before, After: tt.after}); have != tt.want {
+			t.Errorf("-mult-precision=%d: speedup from %v to %v MB/s: want %q have %q", tt.prec, tt.before, tt.after, tt.want, have)
+		}
+	}
+}
+
+func TestFormatStdDev(t *testing.T) {
+	cases := []struct {
+		rsd  float64
+		want string
+	}{
+		{rsd: 0, want: "±0%"},
+		{rsd: 0.0149, want: "±1%"},
+		{rsd: 0.1, want: "±10%"},
+		{rsd: 0.125, want: "±12%!"},
+		{rsd: 1.5, want: "±150%!"},
+	}
+	for _, tt := range cases {
+		if have := formatStdDev(tt.rsd); tt.want != have {
+			t.Errorf("formatStdDev(%g): want %q have %q", tt.rsd, tt.want, have)
+		}
+	}
+}
+
+func TestOpsPerSec(t *testing.T) {
+	cases := []struct {
+		before, after float64
+		old, new      string
+		delta         string
+	}{
+		{before: 100, after: 50, old: "10000000", new: "20000000", delta: "+100.00%"},
+		{before: 50, after: 100, old: "20000000", new: "10000000", delta: "-50.00%"},
+		{before: 0, after: 100, old: "-", new: "10000000", delta: "?"},
+		{before: 100, after: 0, old: "10000000", new: "-", delta: "?"},
+	}
+	for _, tt := range cases {
+		cmp := benchcmp.BenchCmp{
+			Before: &benchcmp.Bench{Name: "BenchmarkOps", NsOp: tt.before},
+			After:  &benchcmp.Bench{Name: "BenchmarkOps", NsOp: tt.after},
+		}
+		if have := formatOps(tt.before); tt.old != have {
+			t.Errorf("formatOps(%g): want %q have %q", tt.before, tt.old, have)
+		}
+		if have := formatOps(tt.after); tt.new != have {
+			t.Errorf("formatOps(%g): want %q have %q", tt.after, tt.new, have)
+		}
+		if have := opsDelta(cmp); tt.delta != have {
+			t.Errorf("opsDelta(%g, %g): want %q have %q", tt.before, tt.after, tt.delta, have)
+		}
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	cases := []struct {
+		ns   float64
+		want string
+	}{
+		{ns: 0, want: "0ns"},
+		{ns: 517, want: "517ns"},
+		{ns: 19600, want: "19.60µs"},
+		{ns: 2.5e6, want: "2.50ms"},
+		{ns: 1.96e9, want: "1.96s"},
+		{ns: 3600e9, want: "3600.00s"},
+	}
+	for _, tt := range cases {
+		if have := formatTime(tt.ns); tt.want != have {
+			t.Errorf("formatTime(%g): want %q have %q", tt.ns, tt.want, have)
+		}
+	}
+}
+
+func TestSelectSections(t *testing.T) {
+	defer func(m map[string]bool) { metrics = m }(metrics)
+
+	metrics = nil
+	if have := selectSections(sections); len(have) != len(sections) {
+		t.Errorf("selectSections without -metric: want %d sections have %d", len(sections), len(have))
+	}
+
+	metrics = map[string]bool{"allocs": true, "B/op": true, "items/op": true}
+	all := append(append([]section(nil), sections...), extraSection("items/op"), extraSection("req/s"))
+	var have []string
+	for _, s := range selectSections(all) {
+		have = append(have, s.unit)
+	}
+	if want := []string{"allocs/op", "B/op", "items/op"}; !reflect.DeepEqual(want, have) {
+		t.Errorf("selectSections: want %v have %v", want, have)
+	}
+
+	for _, name := range []string{"ns/op", "MB/s", "allocs", "bytes", "B/op", "time", "items/op"} {
+		if !validMetric(name) {
+			t.Errorf("validMetric(%q) = false, want true", name)
+		}
+	}
+	for _, name := range []string{"", "alocs", "ns"} {
+		if validMetric(name) {
+			t.Errorf("validMetric(%q) = true, want false", name)
+		}
+	}
+}
+
+func TestIsPercentile(t *testing.T) {
+	for _, unit := range []string{"p50", "p99_ns/op", "p90-ns/op", "p99.9_ns/op", "p999/op"} {
+		if !isPercentile(unit) {
+			t.Errorf("isPercentile(%q) = false, want true", unit)
+		}
+	}
+	for _, unit := range []string{"", "p", "ns/op", "pages/op", "p99x/op", "op_p99", "P99_ns/op"} {
+		if isPercentile(unit) {
+			t.Errorf("isPercentile(%q) = true, want false", unit)
+		}
+	}
+
+	s := extraSection("p99_ns/op")
+	if s.higher {
+		t.Errorf("p99_ns/op is higher is better, want lower")
+	}
+	if have := s.value(&benchcmp.Bench{Extra: map[string]float64{"p99_ns/op": 1234.7}}); have != "1235" {
+		t.Errorf("p99_ns/op value: want 1235 have %s", have)
+	}
+	if have := s.diff(benchcmp.Delta{Before: 1200, After: 1500}); have != "+300" {
+		t.Errorf("p99_ns/op diff: want +300 have %s", have)
+	}
+	if extraSection("p99_req/s").higher {
+		t.Errorf("p99_req/s is higher is better, want lower")
+	}
+}
+
+func TestMinReport(t *testing.T) {
+	defer func(saved int) { *minReport = saved }(*minReport)
+
+	both := benchcmp.NsOp | benchcmp.MbS
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkA", NsOp: 100, MbS: 10, Measured: both},
+			After:  &benchcmp.Bench{Name: "BenchmarkA", NsOp: 90, MbS: 11, Measured: both},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkB", NsOp: 100, MbS: 10, Measured: both},
+			After:  &benchcmp.Bench{Name: "BenchmarkB", NsOp: 90, Measured: benchcmp.NsOp},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkC", NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkC", NsOp: 90, Measured: benchcmp.NsOp},
+		},
+	}
+	for _, tt := range []struct {
+		min  int
+		want []string
+	}{
+		{1, []string{"ns/op", "MB/s"}},
+		{2, []string{"ns/op"}},
+		{4, nil},
+	} {
+		*minReport = tt.min
+		var have []string
+		for _, s := range tableSections(cmps) {
+			have = append(have, s.unit)
+		}
+		if !reflect.DeepEqual(tt.want, have) {
+			t.Errorf("-minreport=%d: want %v have %v", tt.min, tt.want, have)
+		}
+	}
+}
+
+func TestShowMbPercent(t *testing.T) {
+	defer func(saved []section) { sections = saved }(sections)
+	sections = append([]section(nil), sections...)
+
+	showMbPercent()
+	for _, s := range sections {
+		if s.flag != benchcmp.MbS {
+			continue
+		}
+		if s.change != "delta" {
+			t.Errorf("MB/s change header: want delta have %s", s.change)
+		}
+		faster := benchcmp.Delta{Before: 100, After: 125}
+		if want, have := "+25.00%", s.show(faster); want != have {
+			t.Errorf("MB/s change: want %s have %s", want, have)
+		}
+		if s.worsening(faster) >= 0 {
+			t.Errorf("more MB/s is not an improvement")
+		}
+	}
+}
+
+func TestPValueTest(t *testing.T) {
+	defer func(saved string) { *sigTest = saved }(*sigTest)
+
+	x, y := []float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}
+	unit := benchcmp.Unit(benchcmp.NsOp)
+	cmp := benchcmp.BenchCmp{
+		Before: &benchcmp.Bench{Samples: map[string][]float64{unit: x}},
+		After:  &benchcmp.Bench{Samples: map[string][]float64{unit: y}},
+	}
+	for name, test := range tests {
+		*sigTest = name
+		_, want, _ := test(x, y)
+		if have, ok := pValue(cmp, unit); !ok || want != have {
+			t.Errorf("-test=%s: want p=%g have %g", name, want, have)
+		}
+	}
+}
+
+func TestReverse(t *testing.T) {
+	defer func(saved bool) { *reverse = saved }(*reverse)
+
+	cmps := []benchcmp.BenchCmp{
+		{Before: &benchcmp.Bench{Name: "BenchmarkB", NsOp: 100}, After: &benchcmp.Bench{Name: "BenchmarkB", NsOp: 101}},
+		{Before: &benchcmp.Bench{Name: "BenchmarkC", NsOp: 100}, After: &benchcmp.Bench{Name: "BenchmarkC", NsOp: 150}},
+		{Before: &benchcmp.Bench{Name: "BenchmarkA", NsOp: 100}, After: &benchcmp.Bench{Name: "BenchmarkA", NsOp: 90}},
+	}
+	names := func() []string {
+		var names []string
+		for _, cmp := range cmps {
+			names = append(names, cmp.Name())
+		}
+		return names
+	}
+	for _, tt := range []struct {
+		reverse bool
+		by      func([]benchcmp.BenchCmp) sort.Interface
+		want    []string
+	}{
+		{false, func(c []benchcmp.BenchCmp) sort.Interface { return benchcmp.ByName(c) }, []string{"BenchmarkA", "BenchmarkB", "BenchmarkC"}},
+		{true, func(c []benchcmp.BenchCmp) sort.Interface { return benchcmp.ByName(c) }, []string{"BenchmarkC", "BenchmarkB", "BenchmarkA"}},
+		{true, func(c []benchcmp.BenchCmp) sort.Interface { return benchcmp.ByDeltaNsOp(c) }, []string{"BenchmarkB", "BenchmarkA", "BenchmarkC"}},
+	} {
+		*reverse = tt.reverse
+		sort.Sort(ordered(tt.by(cmps)))
+		if have := names(); !reflect.DeepEqual(tt.want, have) {
+			t.Errorf("-reverse=%v: want %v have %v", tt.reverse, tt.want, have)
+		}
+	}
+}
+
+func TestSignedSort(t *testing.T) {
+	defer func(saved bool) { signedSort = saved }(signedSort)
+	signedSort = true
+
+	all := benchcmp.NsOp | benchcmp.MbS
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkFaster", NsOp: 100, MbS: 10, Measured: all},
+			After:  &benchcmp.Bench{Name: "BenchmarkFaster", NsOp: 50, MbS: 20, Measured: all},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkSlower", NsOp: 100, MbS: 10, Measured: all},
+			After:  &benchcmp.Bench{Name: "BenchmarkSlower", NsOp: 110, MbS: 9, Measured: all},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkSame", NsOp: 100, MbS: 10, Measured: all},
+			After:  &benchcmp.Bench{Name: "BenchmarkSame", NsOp: 100, MbS: 10, Measured: all},
+		},
+	}
+	// Regressions come first in each table, whichever direction is worse.
+	want := []string{"BenchmarkSlower", "BenchmarkSame", "BenchmarkFaster"}
+	for _, s := range sections[:2] {
+		sort.Sort(s.order(cmps))
+		var have []string
+		for _, cmp := range cmps {
+			have = append(have, cmp.Name())
+		}
+		if !reflect.DeepEqual(want, have) {
+			t.Errorf("-sort=delta of %s: want %v have %v", s.unit, want, have)
+		}
+	}
+}
+
+func TestAllocRate(t *testing.T) {
+	cases := []struct {
+		b    benchcmp.Bench
+		want string
+	}{
+		{b: benchcmp.Bench{NsOp: 100, BOp: 64}, want: "640.00MB/s"},
+		{b: benchcmp.Bench{NsOp: 1e9, BOp: 3}, want: "3B/s"},
+		{b: benchcmp.Bench{NsOp: 1, BOp: 4096}, want: "4096.00GB/s"},
+		{b: benchcmp.Bench{NsOp: 0, BOp: 64}, want: "0B/s"},
+	}
+	for _, tt := range cases {
+		if have := formatByteRate(allocRateOf(&tt.b)); tt.want != have {
+			t.Errorf("alloc rate of %g ns/op, %d B/op: want %q have %q", tt.b.NsOp, tt.b.BOp, tt.want, have)
+		}
+	}
+
+	both := benchcmp.NsOp | benchcmp.BOp
+	cmps := []struct {
+		before, after int
+		want          bool
+	}{
+		{both, both, true},
+		{both, benchcmp.NsOp, false},
+		{benchcmp.BOp, both, false},
+	}
+	for _, tt := range cmps {
+		cmp := benchcmp.BenchCmp{
+			Before: &benchcmp.Bench{Measured: tt.before},
+			After:  &benchcmp.Bench{Measured: tt.after},
+		}
+		if have := allocRateSection.measured(cmp); tt.want != have {
+			t.Errorf("measured(%b, %b): want %v have %v", tt.before, tt.after, tt.want, have)
+		}
+	}
+}
+
+func TestAllocsRate(t *testing.T) {
+	for _, tt := range []struct {
+		b    benchcmp.Bench
+		want string
+	}{
+		{b: benchcmp.Bench{NsOp: 100, AllocsOp: 2}, want: "20.00M/s"},
+		{b: benchcmp.Bench{NsOp: 1e9, AllocsOp: 3}, want: "3/s"},
+		{b: benchcmp.Bench{NsOp: 2e6, AllocsOp: 5}, want: "2.50k/s"},
+		{b: benchcmp.Bench{NsOp: 0.5, AllocsOp: 1}, want: "2.00G/s"},
+		{b: benchcmp.Bench{NsOp: 0, AllocsOp: 64}, want: "0/s"},
+	} {
+		if have := formatAllocsRate(allocsRateOf(&tt.b)); tt.want != have {
+			t.Errorf("allocs rate of %g ns/op, %d allocs/op: want %q have %q", tt.b.NsOp, tt.b.AllocsOp, tt.want, have)
+		}
+	}
+
+	both := benchcmp.NsOp | benchcmp.AllocsOp
+	for _, tt := range []struct {
+		before, after int
+		want          bool
+	}{
+		{both, both, true},
+		{both, benchcmp.NsOp, false},
+		{benchcmp.AllocsOp | benchcmp.BOp, both, false},
+	} {
+		cmp := benchcmp.BenchCmp{
+			Before: &benchcmp.Bench{Measured: tt.before},
+			After:  &benchcmp.Bench{Measured: tt.after},
+		}
+		if have := allocsRateSection.measured(cmp); tt.want != have {
+			t.Errorf("measured(%b, %b): want %v have %v", tt.before, tt.after, tt.want, have)
+		}
+	}
+}
+
+func TestReportUnmatched(t *testing.T) {
+	defer func(saved io.Writer) { stderr = saved }(stderr)
+	buf := new(bytes.Buffer)
+	stderr = buf
+
+	reportUnmatched("old.txt", []string{"BenchmarkGone", "BenchmarkLost"})
+	reportUnmatched("new.txt", nil)
+	if want, have := "only in old.txt:\n\tBenchmarkGone\n\tBenchmarkLost\n", buf.String(); want != have {
+		t.Errorf("reportUnmatched: want %q have %q", want, have)
+	}
+
+	defer func(saved int) { *maxWarnings = saved }(*maxWarnings)
+	names := []string{"BenchmarkA", "BenchmarkB", "BenchmarkC", "BenchmarkD"}
+	for _, tt := range []struct {
+		max  int
+		want string
+	}{
+		{0, "only in old.txt:\n\tBenchmarkA\n\tBenchmarkB\n\tBenchmarkC\n\tBenchmarkD\n"},
+		{2, "only in old.txt:\n\tBenchmarkA\n\tBenchmarkB\n\t... and 2 more\n"},
+		{4, "only in old.txt:\n\tBenchmarkA\n\tBenchmarkB\n\tBenchmarkC\n\tBenchmarkD\n"},
+	} {
+		*maxWarnings = tt.max
+		buf.Reset()
+		reportUnmatched("old.txt", names)
+		if have := buf.String(); have != tt.want {
+			t.Errorf("reportUnmatched with -max-warnings=%d: want %q have %q", tt.max, tt.want, have)
+		}
+	}
+}
+
+func TestMaxWarningsN(t *testing.T) {
+	defer func(saved io.Writer) { stderr = saved }(stderr)
+	defer func(saved int) { *maxWarnings = saved }(*maxWarnings)
+	buf := new(bytes.Buffer)
+	stderr = buf
+	*maxWarnings = 2
+
+	dir, err := ioutil.TempDir("", "benchcmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	common := "BenchmarkCommon\t100\t10 ns/op\n"
+	var paths []string
+	for i, log := range []string{
+		common + "BenchmarkA\t100\t1 ns/op\nBenchmarkB\t100\t1 ns/op\nBenchmarkC\t100\t1 ns/op\nBenchmarkD\t100\t1 ns/op\n",
+		common,
+		common,
+	} {
+		path := filepath.Join(dir, strconv.Itoa(i+1)+".txt")
+		if err := ioutil.WriteFile(path, []byte(log), 0666); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	restore := discardOutput(t)
+	compareN(paths)
+	restore()
+	want := "" +
+		"benchcmp: WARNING: 4 benchmarks ignored:\n" +
+		"\tignoring BenchmarkA: missing from files 2, 3\n" +
+		"\tignoring BenchmarkB: missing from files 2, 3\n" +
+		"\t... and 2 more\n"
+	if have := buf.String(); have != want {
+		t.Errorf("compareN with -max-warnings=2: want %q have %q", want, have)
+	}
+}
+
+func TestRenderTextN(t *testing.T) {
+	run := func(ns, widgets float64) *benchcmp.Bench {
+		return &benchcmp.Bench{Name: "BenchmarkA", NsOp: ns, Measured: benchcmp.NsOp, Extra: map[string]float64{"widgets/op": widgets}}
+	}
+	cmps := []benchcmp.BenchCmpN{{Benches: []*benchcmp.Bench{run(5, 3), run(6, 4), run(5, 6)}}}
+	buf := new(bytes.Buffer)
+	renderTextN(buf, cmps)
+	want := "" +
+		"benchmark      ns/op #1     ns/op #2     ns/op #3     delta      \n" +
+		"BenchmarkA     5.00         6.00         5.00         +0.00%     \n" +
+		"\n" +
+		"benchmark      widgets/op #1     widgets/op #2     widgets/op #3     delta        \n" +
+		"BenchmarkA     3                 4                 6                 +100.00%     \n"
+	if have := buf.String(); have != want {
+		t.Errorf("renderTextN: want\n%s\nhave\n%s", want, have)
+	}
+
+	// No blank line begins the output when the first table is empty.
+	defer func(saved changedFilter) { changedOnly = saved }(changedOnly)
+	changedOnly = "both"
+	buf.Reset()
+	renderTextN(buf, cmps)
+	if have := buf.String(); !strings.HasPrefix(have, "benchmark      widgets/op #1") {
+		t.Errorf("renderTextN with -changed: have\n%s", have)
+	}
+	changedOnly = ""
+
+	// An extra measurement missing from a middle run has no table.
+	delete(cmps[0].Benches[1].Extra, "widgets/op")
+	buf.Reset()
+	renderTextN(buf, cmps)
+	if strings.Contains(buf.String(), "widgets/op") {
+		t.Errorf("renderTextN of a partly measured metric: have\n%s", buf)
+	}
+}
+
+func TestRenderers(t *testing.T) {
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkA", NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkA", NsOp: 120, Measured: benchcmp.NsOp},
+		},
+	}
+	for name, r := range renderers {
+		buf := new(bytes.Buffer)
+		if err := r.Render(buf, cmps); err != nil {
+			t.Errorf("-format=%s: Render failed: %v", name, err)
+			continue
+		}
+		if !strings.Contains(buf.String(), "BenchmarkA") {
+			t.Errorf("-format=%s: output does not mention BenchmarkA:\n%s", name, buf)
+		}
+	}
+}
+
+func TestPHeader(t *testing.T) {
+	defer func(saved float64) { *confidence = saved }(*confidence)
+
+	for _, tt := range []struct {
+		confidence float64
+		want       string
+	}{
+		{0.95, "p"},
+		{0.99, "p (99% conf.)"},
+		{0.999, "p (99.9% conf.)"},
+	} {
+		*confidence = tt.confidence
+		header := sections[0].header(true)
+		if have := header[len(header)-1]; tt.want != have {
+			t.Errorf("-confidence=%g: want header %q have %q", tt.confidence, tt.want, have)
+		}
+	}
+}
+
+func TestParseFileList(t *testing.T) {
+	defer func(saved io.Writer) { stderr = saved }(stderr)
+	buf := new(bytes.Buffer)
+	stderr = buf
+
+	dir, err := ioutil.TempDir("", "benchcmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	aes, sha := filepath.Join(dir, "aes.txt"), filepath.Join(dir, "sha1.txt")
+	comma := filepath.Join(dir, "a,b.txt")
+	for path, log := range map[string]string{
+		aes:   "pkg: crypto/aes\nBenchmarkEncrypt\t100\t18 ns/op\n",
+		sha:   "pkg: crypto/sha1\nBenchmarkHash\t100\t600 ns/op\nBenchmarkEncrypt\t100\t20 ns/op\n",
+		comma: "BenchmarkHash\t100\t500 ns/op\n",
+	} {
+		if err := ioutil.WriteFile(path, []byte(log), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	log := parseFile(aes + "," + sha)
+	if len(log.Benchmarks) != 2 || log.Pkg != "crypto/aes" {
+		t.Errorf("parseFile of a list: want 2 benchmarks of crypto/aes have %v of %q", log.Benchmarks, log.Pkg)
+	}
+	if b := log.Benchmarks["BenchmarkEncrypt"][0]; b.NsOp != 19 {
+		t.Errorf("parseFile of a list: want BenchmarkEncrypt merged to 19 ns/op have %v", b.NsOp)
+	}
+	if want := "benchcmp: BenchmarkEncrypt is in both " + aes + " and " + sha + "; merging its runs\n"; buf.String() != want {
+		t.Errorf("parseFile of a list: want warning %q have %q", want, buf.String())
+	}
+
+	if have := splitPaths(comma); !reflect.DeepEqual([]string{comma}, have) {
+		t.Errorf("splitPaths(%q): want the file itself have %v", comma, have)
+	}
+	if have := splitPaths("old.txt"); !reflect.DeepEqual([]string{"old.txt"}, have) {
+		t.Errorf("splitPaths(old.txt): have %v", have)
+	}
+}
+
+// TestCompareWithSelf checks that a log compared with itself, whether
+// named twice or read twice from standard input, shows no change in any
+// measurement, as any would mean that parsing it is not deterministic.
+func TestCompareWithSelf(t *testing.T) {
+	defer func(saved io.Writer) { stderr = saved }(stderr)
+	stderr = ioutil.Discard
+	defer func(saved thresholds) { threshold = saved }(threshold)
+	threshold = thresholds{"": 0}
+	defer func(saved *os.File) { os.Stdin = saved }(os.Stdin)
+	defer func(twice bool, data []byte, read bool) {
+		stdinTwice, stdinData, stdinRead = twice, data, read
+	}(stdinTwice, stdinData, stdinRead)
+
+	dir, err := ioutil.TempDir("", "benchcmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.txt")
+	log := "" +
+		"BenchmarkEncrypt\t1000\t17.6 ns/op\t45.40 MB/s\t64 B/op\t1 allocs/op\n" +
+		"BenchmarkEncrypt\t1000\t18.1 ns/op\t44.10 MB/s\t64 B/op\t1 allocs/op\n" +
+		"BenchmarkEncrypt\t1000\t17.9 ns/op\t44.70 MB/s\t64 B/op\t1 allocs/op\n" +
+		"BenchmarkDecrypt\t100\t617 ns/op\t12 req/s\n" +
+		"BenchmarkIdle\t100\t0 ns/op\n"
+	if err := ioutil.WriteFile(path, []byte(log), 0666); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	os.Stdin = f
+	stdinTwice, stdinData, stdinRead = true, nil, false
+
+	for _, p := range []string{path, "-"} {
+		cmps, err := compare(p, p)
+		if err != nil {
+			t.Fatalf("compare(%q, %q) failed: %v", p, p, err)
+		}
+		if len(cmps) != 3 {
+			t.Errorf("compare(%q, %q): want 3 comparisons have %d", p, p, len(cmps))
+		}
+		for _, s := range allSections(cmps) {
+			for _, cmp := range cmps {
+				if d := s.delta(cmp); s.measured(cmp) && d.Changed() {
+					t.Errorf("compare(%q, %q): %s %s changed from %v to %v", p, p, cmp.Name(), s.unit, d.Before, d.After)
+				}
+			}
+		}
+		if regs := findRegressions(cmps, threshold); len(regs) != 0 {
+			t.Errorf("compare(%q, %q): want no regressions have %v", p, p, regs)
+		}
+		if r, ok := worstRegression(cmps); ok {
+			t.Errorf("compare(%q, %q): want no worst regression have %v", p, p, r)
+		}
+	}
+
+	// Named only once, standard input is parsed as it is read.
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	stdinTwice, stdinData, stdinRead = false, nil, false
+	if log, err := readFile("-"); err != nil || len(log.Benchmarks) != 3 || stdinRead {
+		t.Errorf("readFile(-) of a single file: have %v, %v, buffered %v", log, err, stdinRead)
+	}
+}
+
+func TestSelects(t *testing.T) {
+	defer func(saved *regexp.Regexp) { filterRE = saved }(filterRE)
+	defer func(saved *regexp.Regexp) { excludeRE = saved }(excludeRE)
+
+	names := []string{"BenchmarkEncrypt", "BenchmarkDecrypt", "BenchmarkNetDial", "BenchmarkNetEncrypt"}
+	for _, tt := range []struct {
+		filter, exclude string
+		want            []string
+		err             string
+	}{
+		{"", "", names, ""},
+		{"crypt", "", []string{"BenchmarkEncrypt", "BenchmarkDecrypt", "BenchmarkNetEncrypt"}, ""},
+		{"", "Net", []string{"BenchmarkEncrypt", "BenchmarkDecrypt"}, ""},
+		{"Encrypt", "Net", []string{"BenchmarkEncrypt"}, ""},
+		{"", "Benchmark", nil, "every benchmark matches -exclude"},
+		{"Net", "Net", nil, "no benchmarks match -filter but not -exclude"},
+	} {
+		filterRE, excludeRE = nil, nil
+		if tt.filter != "" {
+			filterRE = regexp.MustCompile(tt.filter)
+		}
+		if tt.exclude != "" {
+			excludeRE = regexp.MustCompile(tt.exclude)
+		}
+		var have []string
+		for _, name := range names {
+			if selects(name) {
+				have = append(have, name)
+			}
+		}
+		if !reflect.DeepEqual(tt.want, have) {
+			t.Errorf("-filter=%q -exclude=%q: want %v have %v", tt.filter, tt.exclude, tt.want, have)
+		}
+		if tt.err != "" && errNoneSelected().Error() != tt.err {
+			t.Errorf("-filter=%q -exclude=%q: want error %q have %q", tt.filter, tt.exclude, tt.err, errNoneSelected())
+		}
+	}
+}
+
+func TestCheckEmpty(t *testing.T) {
+	full := &benchcmp.Log{Benchmarks: benchcmp.BenchSet{"BenchmarkA": {{Name: "BenchmarkA", N: 1, NsOp: 1, Measured: benchcmp.NsOp}}}}
+	empty := &benchcmp.Log{}
+	for _, tt := range []struct {
+		logs []*benchcmp.Log
+		want string
+	}{
+		{[]*benchcmp.Log{full, full}, ""},
+		{[]*benchcmp.Log{empty, full}, "no benchmarks in old.txt"},
+		{[]*benchcmp.Log{full, empty}, "no benchmarks in new.txt"},
+		{[]*benchcmp.Log{empty, empty}, "no benchmarks in either old.txt or new.txt"},
+	} {
+		var have string
+		if err := checkEmpty([]string{"old.txt", "new.txt"}, tt.logs); err != nil {
+			have = err.Error()
+		}
+		if have != tt.want {
+			t.Errorf("checkEmpty: want %q have %q", tt.want, have)
+		}
+	}
+	paths := []string{"a.txt", "b.txt", "c.txt"}
+	if err := checkEmpty(paths, []*benchcmp.Log{empty, empty, empty}); err == nil || err.Error() != "no benchmarks in any of a.txt, b.txt, c.txt" {
+		t.Errorf("checkEmpty of three empty logs: have %v", err)
+	}
+}
+
+func TestStrictErrors(t *testing.T) {
+	in := "BenchmarkA\t100\t50 ns/op\n" +
+		"BenchmarkB\t100\t-50 ns/op\n" +
+		"BenchmarkC\t100\t5x0 ns/op\n" +
+		"BenchmarkD\t100\t50 ns/op\n" +
+		"BenchmarkA\t100\t52 ns/op\n"
+	log, err := benchcmp.ParseLog(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	want := []string{
+		"benchcmp: new.txt: malformed line 3: BenchmarkC\t100\t5x0 ns/op",
+		"benchcmp: new.txt: negative measurement at line 2: BenchmarkB\t100\t-50 ns/op",
+		"benchcmp: new.txt: BenchmarkA ran again after other benchmarks",
+	}
+	if have := strictErrors("new.txt", log); !reflect.DeepEqual(want, have) {
+		t.Errorf("strictErrors: want %q have %q", want, have)
+	}
+
+	log, err = benchcmp.ParseLog(strings.NewReader("BenchmarkA\t100\t50 ns/op\n--- SKIP: BenchmarkS\n"))
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	if have := strictErrors("new.txt", log); have != nil {
+		t.Errorf("strictErrors of a clean log: want nil have %q", have)
+	}
+}
+
+func TestWeightedGeoMean(t *testing.T) {
+	defer func(saved bool) { *weighted = saved }(*weighted)
+
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkMicro", N: 1000, NsOp: 10, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkMicro", N: 1000, NsOp: 20, Measured: benchcmp.NsOp},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkHeavy", N: 10, NsOp: 3000, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkHeavy", N: 10, NsOp: 1500, Measured: benchcmp.NsOp},
+		},
+	}
+	for _, tt := range []struct {
+		weighted bool
+		label    string
+		want     string
+	}{
+		{false, "[geomean of 2]", "+0.00%"},
+		{true, "[weighted geomean of 2]", "-29.29%"},
+	} {
+		*weighted = tt.weighted
+		d, n := sections[0].geoMean(cmps)
+		if have := sections[0].show(d); have != tt.want || geoMeanLabel(n) != tt.label {
+			t.Errorf("-weighted=%v: want %s %s have %s %s", tt.weighted, tt.label, tt.want, geoMeanLabel(n), have)
+		}
+	}
+}
+
+func TestMemoryOnly(t *testing.T) {
+	// BenchmarkMem reports allocations and bytes but not ns/op.
+	parse := func(log string) benchcmp.BenchSet {
+		bb, err := benchcmp.ParseBenchSet(strings.NewReader(log))
+		if err != nil {
+			t.Fatalf("ParseBenchSet failed: %v", err)
+		}
+		return bb
+	}
+	before := parse("BenchmarkMem\t100\t8 B/op\t1 allocs/op\nBenchmarkTime\t100\t5 ns/op\n")
+	after := parse("BenchmarkMem\t100\t16 B/op\t2 allocs/op\nBenchmarkTime\t100\t5 ns/op\n")
+	cmps, _ := benchcmp.Correlate(before, after)
+	if len(cmps) != 2 {
+		t.Fatalf("Correlate dropped a memory-only benchmark: have %d comparisons", len(cmps))
+	}
+
+	buf := new(bytes.Buffer)
+	if err := renderText(buf, cmps); err != nil {
+		t.Fatalf("renderText failed: %v", err)
+	}
+	tables := strings.Split(buf.String(), "\n\n")
+	if len(tables) != 3 {
+		t.Fatalf("renderText: want 3 tables have\n%s", buf)
+	}
+	if strings.Contains(tables[0], "BenchmarkMem") {
+		t.Errorf("renderText: memory-only benchmark in the ns/op table:\n%s", tables[0])
+	}
+	for _, table := range tables[1:] {
+		if !strings.Contains(table, "BenchmarkMem") || !strings.Contains(table, "+100.00%") {
+			t.Errorf("renderText: memory-only benchmark missing from its table:\n%s", table)
+		}
+	}
+}
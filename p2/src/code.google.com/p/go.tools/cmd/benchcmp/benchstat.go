@@ -0,0 +1,131 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// errBenchstatDelta is returned for benchstat output comparing
+// several files, which holds no single run to compare.
+var errBenchstatDelta = errors.New("benchstat output compares several files; give benchstat one file at a time")
+
+// Scales from the units benchstat prints values in to the units of
+// testing.B, by metric.
+var (
+	timeScale  = map[string]float64{"ps": 1e-3, "ns": 1, "µs": 1e3, "μs": 1e3, "us": 1e3, "ms": 1e6, "s": 1e9}
+	byteScale  = map[string]float64{"B": 1, "kB": 1e3, "MB": 1e6, "GB": 1e9, "TB": 1e12}
+	countScale = map[string]float64{"": 1, "k": 1e3, "M": 1e6, "G": 1e9, "T": 1e12}
+)
+
+// parseBenchstat parses the output of benchstat for a single file from
+// r: a table per metric, headed by "name" and the metric, of rows
+// holding a benchmark name and its mean ± its variation. Benchstat
+// drops the Benchmark prefix from each name, so it is restored to match
+// the names in go test output. The variation is ignored, so changes
+// between files of benchstat output are never judged insignificant.
+func parseBenchstat(r io.Reader) (benchcmp.BenchSet, error) {
+	bb := make(benchcmp.BenchSet)
+	var metric string // metric of the current table, or "" between tables
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		fields := strings.Fields(s.Text())
+		switch {
+		case len(fields) == 0:
+			metric = ""
+		case fields[0] == "name":
+			if len(fields) != 2 {
+				return nil, errBenchstatDelta
+			}
+			metric = fields[1]
+		case metric == "" || strings.HasPrefix(fields[0], "["):
+			// Configuration lines such as "pkg: fmt", and
+			// summary rows such as "[Geo mean]".
+		default:
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("line %d: no value for %s", n, fields[0])
+			}
+			if err := addBenchstat(bb, "Benchmark"+fields[0], metric, fields[1]); err != nil {
+				return nil, fmt.Errorf("line %d: %v", n, err)
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return bb, nil
+}
+
+// addBenchstat records in bb that the named benchmark measured value,
+// as formatted by benchstat, in metric.
+func addBenchstat(bb benchcmp.BenchSet, name, metric, value string) error {
+	var b *benchcmp.Bench
+	if benches := bb[name]; len(benches) > 0 {
+		b = benches[0]
+	} else {
+		// Each benchmark is added once, so those before it
+		// number len(bb).
+		b = &benchcmp.Bench{Name: name, N: 1}
+		bb.Add(b, len(bb))
+	}
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return unicode.IsLetter(r) && r != 'e' && r != 'E' || r == '/'
+	})
+	if i < 0 {
+		i = len(value)
+	}
+	v, err := strconv.ParseFloat(value[:i], 64)
+	if err != nil {
+		return fmt.Errorf("invalid value %q", value)
+	}
+	suffix := value[i:]
+	var scale float64
+	var ok bool
+	switch metric {
+	case "time/op":
+		scale, ok = timeScale[suffix]
+		b.NsOp = v * scale
+		b.Measured |= benchcmp.NsOp
+	case "speed":
+		scale, ok = byteScale[strings.TrimSuffix(suffix, "/s")]
+		ok = ok && strings.HasSuffix(suffix, "/s")
+		b.MbS = v * scale / 1e6
+		b.Measured |= benchcmp.MbS
+	case "alloc/op":
+		scale, ok = byteScale[suffix]
+		b.BOp = uint64(v*scale + 0.5)
+		b.Measured |= benchcmp.BOp
+	case "allocs/op":
+		scale, ok = countScale[suffix]
+		b.AllocsOp = uint64(v*scale + 0.5)
+		b.Measured |= benchcmp.AllocsOp
+	default:
+		scale, ok = countScale[suffix]
+		if b.Extra == nil {
+			b.Extra = make(map[string]float64)
+		}
+		b.Extra[metric] = v * scale
+	}
+	if !ok {
+		return fmt.Errorf("invalid %s %q", metric, value)
+	}
+	return nil
+}
+
+// isBenchstat reports whether the input buffered by br looks like the
+// output of benchstat, which marks the variation of each value with ±.
+func isBenchstat(br *bufio.Reader) bool {
+	buf, _ := br.Peek(4096)
+	return bytes.Contains(buf, []byte("±"))
+}
@@ -0,0 +1,68 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestParseBenchstat(t *testing.T) {
+	const out = `pkg: crypto/aes
+name       time/op
+Encrypt-8  19.6ns ± 2%
+Decrypt-8  1.20µs ± 1%
+[Geo mean]  153ns
+
+name       speed
+Encrypt-8  818MB/s ± 2%
+
+name       alloc/op
+Encrypt-8  1.50kB ± 0%
+
+name       allocs/op
+Encrypt-8   5.00 ± 0%
+
+name       items/op
+Decrypt-8  2.50k ± 3%
+`
+	br := bufio.NewReader(strings.NewReader(out))
+	if !isBenchstat(br) {
+		t.Fatalf("benchstat output not detected")
+	}
+	bb, err := parseBenchstat(br)
+	if err != nil {
+		t.Fatalf("parseBenchstat failed: %v", err)
+	}
+	if len(bb) != 2 || len(bb["BenchmarkEncrypt-8"]) != 1 || len(bb["BenchmarkDecrypt-8"]) != 1 {
+		t.Fatalf("parseBenchstat: wrong benchmarks %v", bb)
+	}
+	enc := bb["BenchmarkEncrypt-8"][0]
+	all := benchcmp.NsOp | benchcmp.MbS | benchcmp.BOp | benchcmp.AllocsOp
+	if enc.NsOp != 19.6 || enc.MbS != 818 || enc.BOp != 1500 || enc.AllocsOp != 5 || enc.Measured != all {
+		t.Errorf("parseBenchstat: want BenchmarkEncrypt-8 19.6 ns/op 818 MB/s 1500 B/op 5 allocs/op have %v", enc)
+	}
+	dec := bb["BenchmarkDecrypt-8"][0]
+	if dec.NsOp != 1200 || dec.Extra["items/op"] != 2500 || dec.Measured != benchcmp.NsOp {
+		t.Errorf("parseBenchstat: want BenchmarkDecrypt-8 1200 ns/op 2500 items/op have %v", dec)
+	}
+
+	for _, in := range []string{
+		"name  old time/op  new time/op  delta\nEncrypt-8  19.6ns ± 2%  17.6ns ± 1%  -10.20%\n",
+		"name  time/op\nEncrypt-8  19.6ly ± 2%\n",
+		"name  speed\nEncrypt-8  818MB ± 2%\n",
+		"name  time/op\nEncrypt-8\n",
+	} {
+		if _, err := parseBenchstat(strings.NewReader(in)); err == nil {
+			t.Errorf("parseBenchstat(%q) should have failed", in)
+		}
+	}
+	if isBenchstat(bufio.NewReader(strings.NewReader("BenchmarkA\t100\t5 ns/op\n"))) {
+		t.Errorf("go test output detected as benchstat output")
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "code.google.com/p/go.tools/benchcmp"
+
+// clamped reports whether d changes by more than pct percent, as
+// -max-delta limits for sorting. Any change from zero does.
+func clamped(d benchcmp.Delta, pct float64) bool {
+	return pct > 0 && d.ChangedBy(pct)
+}
+
+// clampDelta returns d, or if it is clamped, a Delta changing by
+// exactly pct percent in the same direction, so that near-zero
+// baselines with absurd ratios do not dominate a sort by magnitude.
+func clampDelta(d benchcmp.Delta, pct float64) benchcmp.Delta {
+	if !clamped(d, pct) {
+		return d
+	}
+	if d.After > d.Before {
+		return benchcmp.Delta{Before: 1, After: 1 + pct/100}
+	}
+	return benchcmp.Delta{Before: 1, After: 1 - pct/100}
+}
+
+// showClamped formats the change d as s.show does, marked with ">"
+// if it is beyond -max-delta.
+func (s section) showClamped(d benchcmp.Delta) string {
+	if clamped(d, *maxDelta) {
+		return ">" + s.show(d)
+	}
+	return s.show(d)
+}
@@ -0,0 +1,68 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestClampDelta(t *testing.T) {
+	for _, tt := range []struct {
+		d, want benchcmp.Delta
+	}{
+		{benchcmp.Delta{Before: 100, After: 150}, benchcmp.Delta{Before: 100, After: 150}},
+		{benchcmp.Delta{Before: 0.01, After: 5}, benchcmp.Delta{Before: 1, After: 3}},
+		{benchcmp.Delta{Before: 0, After: 5}, benchcmp.Delta{Before: 1, After: 3}},
+		{benchcmp.Delta{Before: 100, After: 10}, benchcmp.Delta{Before: 100, After: 10}},
+		{benchcmp.Delta{Before: 0, After: 0}, benchcmp.Delta{Before: 0, After: 0}},
+	} {
+		if have := clampDelta(tt.d, 200); have != tt.want {
+			t.Errorf("clampDelta(%v, 200): want %v have %v", tt.d, tt.want, have)
+		}
+	}
+	if have, want := clampDelta(benchcmp.Delta{Before: 100, After: 10}, 50), (benchcmp.Delta{Before: 1, After: 0.5}); have != want {
+		t.Errorf("clampDelta of a decrease: want %v have %v", want, have)
+	}
+}
+
+func TestMaxDelta(t *testing.T) {
+	defer func(saved float64) { *maxDelta = saved }(*maxDelta)
+
+	mk := func(name string, before, after float64) benchcmp.BenchCmp {
+		return benchcmp.BenchCmp{
+			Before: &benchcmp.Bench{Name: name, NsOp: before, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: name, NsOp: after, Measured: benchcmp.NsOp},
+		}
+	}
+	cmps := []benchcmp.BenchCmp{mk("BenchmarkTiny", 0.01, 5), mk("BenchmarkReal", 100, 400), mk("BenchmarkSmall", 100, 110)}
+	names := func() []string {
+		sort.Sort(sections[0].order(cmps))
+		var names []string
+		for _, cmp := range cmps {
+			names = append(names, cmp.Name())
+		}
+		return names
+	}
+
+	*maxDelta = 0
+	if want := []string{"BenchmarkTiny", "BenchmarkReal", "BenchmarkSmall"}; !reflect.DeepEqual(want, names()) {
+		t.Errorf("unclamped sort: want %v have %v", want, names())
+	}
+	// Clamped at +200%, the tiny baseline ties with the real +300%.
+	*maxDelta = 200
+	if want := []string{"BenchmarkReal", "BenchmarkTiny", "BenchmarkSmall"}; !reflect.DeepEqual(want, names()) {
+		t.Errorf("-max-delta=200 sort: want %v have %v", want, names())
+	}
+	if have := sections[0].showClamped(cmps[1].DeltaNsOp()); have != ">+49900.00%" {
+		t.Errorf("showClamped: want >+49900.00%% have %s", have)
+	}
+	if have := sections[0].showClamped(cmps[2].DeltaNsOp()); have != "+10.00%" {
+		t.Errorf("showClamped within -max-delta: want +10.00%% have %s", have)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "os"
+
+// useColor reports whether text output should be colored, per -color.
+var useColor bool
+
+// ANSI escape sequences used to color changes. They take no width in
+// the tables written by tableWriter, and are all the same length, so
+// that colored cells also stay aligned for tools that count bytes.
+const (
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiDefault = "\x1b[39m"
+	ansiReset   = "\x1b[0m"
+)
+
+// colorize wraps s in ANSI escape sequences coloring it red if sign is
+// positive (a regression), green if negative (an improvement), and the
+// default color otherwise.
+func colorize(s string, sign int) string {
+	color := ansiDefault
+	switch {
+	case sign > 0:
+		color = ansiRed
+	case sign < 0:
+		color = ansiGreen
+	}
+	return color + s + ansiReset
+}
+
+// paint is like colorize, but leaves s unchanged if color is disabled.
+func paint(s string, sign int) string {
+	if !useColor {
+		return s
+	}
+	return colorize(s, sign)
+}
+
+// sign returns the sign of f as -1, 0, or +1.
+func sign(f float64) int {
+	switch {
+	case f > 0:
+		return 1
+	case f < 0:
+		return -1
+	}
+	return 0
+}
+
+// isTerminal reports whether f appears to be a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
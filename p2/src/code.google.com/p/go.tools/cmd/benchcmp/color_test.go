@@ -0,0 +1,28 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+func TestColorize(t *testing.T) {
+	cases := []struct {
+		sign int
+		want string
+	}{
+		{sign: 1, want: "\x1b[31m+5.00%\x1b[0m"},
+		{sign: -1, want: "\x1b[32m+5.00%\x1b[0m"},
+		{sign: 0, want: "\x1b[39m+5.00%\x1b[0m"},
+	}
+	for _, tt := range cases {
+		if have := colorize("+5.00%", tt.sign); have != tt.want {
+			t.Errorf("colorize(%d): want %q have %q", tt.sign, tt.want, have)
+		}
+	}
+
+	// Colored cells must all have the same length in bytes.
+	if len(colorize("x", 1)) != len(colorize("x", -1)) || len(colorize("x", 1)) != len(colorize("x", 0)) {
+		t.Errorf("colorize produces cells of differing widths")
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// configMismatches returns a description of each configuration reported
+// by logs, read from paths, that differs from that of the first log.
+// Configuration that a log does not report is not compared.
+func configMismatches(paths []string, logs []*benchcmp.Log) []string {
+	var diffs []string
+	first := logs[0]
+	for i, log := range logs[1:] {
+		fields := []struct {
+			key      string
+			old, new string
+		}{
+			{"goos", first.GOOS, log.GOOS},
+			{"goarch", first.GOARCH, log.GOARCH},
+			{"pkg", first.Pkg, log.Pkg},
+		}
+		for _, f := range fields {
+			if f.old != "" && f.new != "" && f.old != f.new {
+				diffs = append(diffs, fmt.Sprintf("%s is %s in %s but %s in %s", f.key, f.old, paths[0], f.new, paths[i+1]))
+			}
+		}
+	}
+	return diffs
+}
+
+// warnConfig warns on standard error if logs, read from paths, appear
+// to be from different platforms or packages. It is silenced by -force.
+func warnConfig(paths []string, logs []*benchcmp.Log) {
+	diffs := configMismatches(paths, logs)
+	for _, d := range diffs {
+		fmt.Fprintf(stderr, "benchcmp: WARNING: %s\n", d)
+	}
+	if len(diffs) > 0 {
+		fmt.Fprintln(stderr, "benchcmp: WARNING: the comparison may be meaningless; use -force to silence this warning")
+	}
+}
+
+// warnFewMatches warns on standard error if fewer than -minmatch percent
+// of the benchmarks in the smaller of the files at oldPath and newPath,
+// holding nOld and nNew benchmarks, matched, as when the files are from
+// different packages. It is silenced by -force.
+func warnFewMatches(oldPath, newPath string, matched, nOld, nNew int) {
+	smaller := nOld
+	if nNew < smaller {
+		smaller = nNew
+	}
+	if smaller == 0 || float64(matched) >= *minMatch/100*float64(smaller) {
+		return
+	}
+	fmt.Fprintf(stderr, "benchcmp: WARNING: only %d benchmarks match, of %d in %s and %d in %s\n", matched, nOld, oldPath, nNew, newPath)
+	fmt.Fprintln(stderr, "benchcmp: WARNING: the files may be from different packages; use -force to silence this warning")
+}
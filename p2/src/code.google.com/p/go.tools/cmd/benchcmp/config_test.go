@@ -0,0 +1,67 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestConfigMismatches(t *testing.T) {
+	paths := []string{"old.txt", "mid.txt", "new.txt"}
+	logs := []*benchcmp.Log{
+		{GOOS: "linux", GOARCH: "amd64", Pkg: "crypto/aes"},
+		{GOOS: "linux", GOARCH: "amd64"},
+		{GOOS: "darwin", GOARCH: "arm64", Pkg: "crypto/aes"},
+	}
+	want := []string{
+		"goos is linux in old.txt but darwin in new.txt",
+		"goarch is amd64 in old.txt but arm64 in new.txt",
+	}
+	if have := configMismatches(paths, logs); !reflect.DeepEqual(want, have) {
+		t.Errorf("configMismatches:\nwant %q\nhave %q", want, have)
+	}
+
+	same := []*benchcmp.Log{{GOOS: "linux"}, {GOOS: "linux"}}
+	if have := configMismatches(paths[:2], same); len(have) != 0 {
+		t.Errorf("configMismatches of matching logs: want none have %q", have)
+	}
+}
+
+func TestWarnFewMatches(t *testing.T) {
+	defer func(saved io.Writer) { stderr = saved }(stderr)
+	defer func(saved float64) { *minMatch = saved }(*minMatch)
+	buf := new(bytes.Buffer)
+	stderr = buf
+
+	*minMatch = 20
+	for _, tt := range []struct {
+		matched, nOld, nNew int
+		warn                bool
+	}{
+		{2, 200, 180, true},
+		{36, 200, 180, false},
+		{35, 200, 180, true},
+		{1, 1, 200, false},
+		{0, 0, 10, false},
+	} {
+		buf.Reset()
+		warnFewMatches("old.txt", "new.txt", tt.matched, tt.nOld, tt.nNew)
+		if warned := buf.Len() > 0; warned != tt.warn {
+			t.Errorf("warnFewMatches(%d, %d, %d): want warning %v have %q", tt.matched, tt.nOld, tt.nNew, tt.warn, buf.String())
+		}
+	}
+
+	buf.Reset()
+	warnFewMatches("old.txt", "new.txt", 2, 200, 180)
+	if want := "only 2 benchmarks match, of 200 in old.txt and 180 in new.txt"; !strings.Contains(buf.String(), want) {
+		t.Errorf("warnFewMatches: want %q in %q", want, buf.String())
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// csvHeader is the first row written by renderCSV.
+var csvHeader = []string{"benchmark", "metric", "old", "new", "delta"}
+
+// csvRows returns one row, as written by renderCSV and renderTSV, for
+// each measurement selected by -metric and recorded by both sides of
+// each BenchCmp. The delta is a plain percent change, or empty if it
+// is not finite.
+func csvRows(cmps []benchcmp.BenchCmp) [][]string {
+	var rows [][]string
+	all := selectSections(allSections(cmps))
+	for _, cmp := range cmps {
+		for _, s := range all {
+			if !s.measured(cmp) {
+				continue
+			}
+			d := s.delta(cmp)
+			pct := ""
+			if f := d.Float64(); !math.IsInf(f, 0) {
+				pct = strconv.FormatFloat(100*f-100, 'f', 2, 64)
+			}
+			rows = append(rows, []string{cmp.Name(), s.unit, formatFloat(d.Before), formatFloat(d.After), pct})
+		}
+	}
+	return rows
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// renderCSV writes cmps to w as CSV, one row per benchmark and metric,
+// after the header of -header as comment lines.
+func renderCSV(w io.Writer, cmps []benchcmp.BenchCmp) error {
+	writeCommentHeader(w)
+	cw := csv.NewWriter(w)
+	cw.Write(csvHeader)
+	for _, row := range csvRows(cmps) {
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
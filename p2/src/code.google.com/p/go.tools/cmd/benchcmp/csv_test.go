@@ -0,0 +1,41 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestRenderCSV(t *testing.T) {
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkTime", NsOp: 100, Measured: benchcmp.NsOp, Extra: map[string]float64{"items/op": 4}},
+			After:  &benchcmp.Bench{Name: "BenchmarkTime", NsOp: 150, Measured: benchcmp.NsOp, Extra: map[string]float64{"items/op": 5}},
+		},
+		{
+			Before: &benchcmp.Bench{Name: `BenchmarkOdd,"Name"`, NsOp: 2.5, BOp: 0, AllocsOp: 4, Measured: benchcmp.NsOp | benchcmp.BOp | benchcmp.AllocsOp},
+			After:  &benchcmp.Bench{Name: `BenchmarkOdd,"Name"`, NsOp: 1.25, BOp: 8, AllocsOp: 4, Measured: benchcmp.NsOp | benchcmp.BOp | benchcmp.AllocsOp},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := renderCSV(buf, cmps); err != nil {
+		t.Fatalf("renderCSV failed: %v", err)
+	}
+
+	want := `benchmark,metric,old,new,delta
+BenchmarkTime,ns/op,100,150,50.00
+BenchmarkTime,items/op,4,5,25.00
+"BenchmarkOdd,""Name""",ns/op,2.5,1.25,-50.00
+"BenchmarkOdd,""Name""",allocs/op,4,4,0.00
+"BenchmarkOdd,""Name""",B/op,0,8,
+`
+	if have := buf.String(); want != have {
+		t.Errorf("renderCSV incorrect output:\nwant %q\nhave %q", want, have)
+	}
+}
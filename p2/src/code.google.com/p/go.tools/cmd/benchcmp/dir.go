@@ -0,0 +1,106 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// isDir reports whether path names a directory.
+// Standard input (-) is never a directory.
+func isDir(path string) bool {
+	if path == "-" {
+		return false
+	}
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// compareDirs compares each file in oldDir with the file of the same
+// name in newDir, typically the benchmarks of one package, printing a
+// header naming each package before its comparison. Files found in
+// only one directory, and pairs with nothing to compare, are reported
+// to standard error. It returns the comparisons of every package, for
+// -ci to check.
+func compareDirs(oldDir, newDir string) []benchcmp.BenchCmp {
+	oldFiles, newFiles := readDirFiles(oldDir), readDirFiles(newDir)
+	var all []benchcmp.BenchCmp
+	var shown bool // Has any package been displayed yet?
+	for _, name := range oldFiles {
+		if !containsString(newFiles, name) {
+			fmt.Fprintf(stderr, "benchcmp: %s only in %s\n", name, oldDir)
+			continue
+		}
+		cmps, err := compare(filepath.Join(oldDir, name), filepath.Join(newDir, name))
+		if err != nil {
+			fmt.Fprintf(stderr, "benchcmp: %s: %v\n", name, err)
+			continue
+		}
+		if shown {
+			fmt.Fprintln(stdout)
+		}
+		shown = true
+		fmt.Fprintf(stdout, "pkg: %s\n\n", packageName(name))
+		output(textRenderer{}, cmps)
+		all = append(all, cmps...)
+	}
+	for _, name := range newFiles {
+		if !containsString(oldFiles, name) {
+			fmt.Fprintf(stderr, "benchcmp: %s only in %s\n", name, newDir)
+		}
+	}
+	closeOutput()
+
+	if len(all) == 0 {
+		fatal("benchcmp: no repeated benchmarks")
+	}
+	return all
+}
+
+// readDirFiles returns the sorted names of the regular files in dir,
+// ignoring hidden ones.
+func readDirFiles(dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		fatal(err)
+	}
+	var names []string
+	for _, fi := range infos {
+		if isLogFile(fi) {
+			names = append(names, fi.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isLogFile reports whether fi describes a file that may hold a log
+// of benchmarks: a regular file that is not hidden.
+func isLogFile(fi os.FileInfo) bool {
+	return fi.Mode().IsRegular() && !strings.HasPrefix(fi.Name(), ".")
+}
+
+// packageName returns the name of the package whose benchmarks are in
+// the file with the given name: the name without its extensions,
+// such as net_http for net_http.txt.gz.
+func packageName(file string) string {
+	if i := strings.Index(file, "."); i > 0 {
+		return file[:i]
+	}
+	return file
+}
+
+// containsString reports whether list, which must be sorted, contains s.
+func containsString(list []string, s string) bool {
+	i := sort.SearchStrings(list, s)
+	return i < len(list) && list[i] == s
+}
@@ -0,0 +1,54 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadDirFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchcmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"net_http.txt", "fmt.txt.gz", ".hidden"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	if !isDir(dir) || isDir(filepath.Join(dir, "fmt.txt.gz")) || isDir("-") {
+		t.Errorf("isDir misclassified a path")
+	}
+	want := []string{"fmt.txt.gz", "net_http.txt"}
+	if have := readDirFiles(dir); !reflect.DeepEqual(want, have) {
+		t.Errorf("readDirFiles: want %v have %v", want, have)
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	cases := []struct {
+		file, want string
+	}{
+		{"net_http.txt", "net_http"},
+		{"fmt.txt.gz", "fmt"},
+		{"strings", "strings"},
+		{".bench", ".bench"},
+	}
+	for _, tt := range cases {
+		if have := packageName(tt.file); tt.want != have {
+			t.Errorf("packageName(%q): want %q have %q", tt.file, tt.want, have)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// githubEscaper escapes the data of a GitHub Actions workflow command.
+var githubEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// renderGitHub writes a GitHub Actions workflow command to w for each
+// regression in cmps beyond -threshold, so that the regressions are
+// shown as annotations. They are errors with -ci, and warnings otherwise.
+func renderGitHub(w io.Writer, cmps []benchcmp.BenchCmp) error {
+	level := "warning"
+	if *ciMode {
+		level = "error"
+	}
+	for _, r := range findRegressions(cmps, threshold) {
+		msg := fmt.Sprintf("%s (threshold %.2f%%)", r, r.threshold)
+		if _, err := fmt.Fprintf(w, "::%s::%s\n", level, githubEscaper.Replace(msg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
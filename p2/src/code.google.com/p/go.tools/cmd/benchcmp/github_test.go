@@ -0,0 +1,44 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestRenderGitHub(t *testing.T) {
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkSlower", NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkSlower", NsOp: 110, Measured: benchcmp.NsOp},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkFaster", NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkFaster", NsOp: 50, Measured: benchcmp.NsOp},
+		},
+	}
+
+	defer func(ci bool) { *ciMode = ci }(*ciMode)
+	cases := []struct {
+		ci   bool
+		want string
+	}{
+		{ci: false, want: "::warning::BenchmarkSlower ns/op regressed: +10.00%25 (threshold 5.00%25)\n"},
+		{ci: true, want: "::error::BenchmarkSlower ns/op regressed: +10.00%25 (threshold 5.00%25)\n"},
+	}
+	for _, tt := range cases {
+		*ciMode = tt.ci
+		buf := new(bytes.Buffer)
+		if err := renderGitHub(buf, cmps); err != nil {
+			t.Fatalf("renderGitHub failed: %v", err)
+		}
+		if have := buf.String(); tt.want != have {
+			t.Errorf("renderGitHub(ci=%t): want %q have %q", tt.ci, tt.want, have)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// otherGroup is the group of benchmarks whose names have no prefix.
+const otherGroup = "other"
+
+// groupKey returns the group of the benchmark named name with
+// -group=prefix: the leading token of its name after "Benchmark",
+// up to the first "_" or "/", as PkgA of BenchmarkPkgA_Encode-8.
+// Names without such a token are in otherGroup.
+func groupKey(name string) string {
+	base, _ := benchcmp.SplitName(name)
+	base = strings.TrimPrefix(base, "Benchmark")
+	i := strings.IndexAny(base, "_/")
+	if i <= 0 {
+		return otherGroup
+	}
+	return base[:i]
+}
+
+// groupByPrefix groups cmps by groupKey. It returns the groups in the
+// order of their first benchmark in cmps, with otherGroup last.
+func groupByPrefix(cmps []benchcmp.BenchCmp) ([]string, map[string][]benchcmp.BenchCmp) {
+	groups := make(map[string][]benchcmp.BenchCmp)
+	var keys []string
+	for _, cmp := range cmps {
+		k := groupKey(cmp.Name())
+		if _, ok := groups[k]; !ok && k != otherGroup {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], cmp)
+	}
+	if _, ok := groups[otherGroup]; ok {
+		keys = append(keys, otherGroup)
+	}
+	return keys, groups
+}
+
+// renderGroups writes the tables of renderText for each group of cmps
+// by groupByPrefix, each headed by the group's name and the geometric
+// mean of its changes in ns/op.
+func renderGroups(w io.Writer, cmps []benchcmp.BenchCmp) error {
+	keys, groups := groupByPrefix(cmps)
+	for i, k := range keys {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "group %s", k)
+		if d, n := sections[0].geoMean(groups[k]); n > 0 {
+			fmt.Fprintf(w, ": ns/op geomean %s of %d", sections[0].show(d), n)
+		}
+		fmt.Fprint(w, "\n\n")
+		if err := renderText(w, groups[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestGroupKey(t *testing.T) {
+	for _, tt := range []struct{ name, want string }{
+		{"BenchmarkPkgA_Encode", "PkgA"},
+		{"BenchmarkPkgA_Encode-8", "PkgA"},
+		{"BenchmarkJSON/Decode/small-4", "JSON"},
+		{"BenchmarkHTTP_Get/keepalive", "HTTP"},
+		{"BenchmarkSHA256_1K_Unaligned", "SHA256"},
+		{"BenchmarkEncrypt", otherGroup},
+		{"BenchmarkEncrypt-8", otherGroup},
+		{"Benchmark_Underscore", otherGroup},
+		{"Benchmark/sub", otherGroup},
+	} {
+		if have := groupKey(tt.name); have != tt.want {
+			t.Errorf("groupKey(%q): want %q have %q", tt.name, tt.want, have)
+		}
+	}
+}
+
+func TestGroupByPrefix(t *testing.T) {
+	var cmps []benchcmp.BenchCmp
+	for _, name := range []string{"BenchmarkFoo", "BenchmarkB_X", "BenchmarkA_X", "BenchmarkB_Y"} {
+		cmps = append(cmps, benchcmp.BenchCmp{
+			Before: &benchcmp.Bench{Name: name, NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: name, NsOp: 100, Measured: benchcmp.NsOp},
+		})
+	}
+	keys, groups := groupByPrefix(cmps)
+	if want := []string{"B", "A", otherGroup}; !reflect.DeepEqual(want, keys) {
+		t.Errorf("groupByPrefix: want groups %v have %v", want, keys)
+	}
+	if len(groups["B"]) != 2 || groups["B"][1].Name() != "BenchmarkB_Y" || len(groups[otherGroup]) != 1 {
+		t.Errorf("groupByPrefix: wrong groups %v", groups)
+	}
+}
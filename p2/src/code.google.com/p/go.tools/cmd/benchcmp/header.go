@@ -0,0 +1,118 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// An inputFile is a file compared, as described by -header.
+type inputFile struct {
+	Role     string `json:"role"`               // old, mid, or new
+	Path     string `json:"path"`               // as given, or - for standard input
+	Modified string `json:"modified,omitempty"` // modification time, in RFC 3339 format
+}
+
+// inputs holds the files compared, as described by -header, or nil
+// without -header.
+var inputs []inputFile
+
+// headerFormats lists the formats that -header applies to.
+var headerFormats = map[string]bool{"text": true, "json": true, "yaml": true, "csv": true, "tsv": true}
+
+// newInputs returns the description of the files compared, in order,
+// the first being old, the last new, and any others mid. The
+// modification time of standard input, or of a file that cannot be
+// read, is left empty.
+func newInputs(paths []string) []inputFile {
+	files := make([]inputFile, len(paths))
+	for i, path := range paths {
+		role := "mid"
+		switch i {
+		case 0:
+			role = "old"
+		case len(paths) - 1:
+			role = "new"
+		}
+		files[i] = inputFile{Role: role, Path: path}
+		if path == "-" {
+			continue
+		}
+		if fi, err := os.Stat(path); err == nil {
+			files[i].Modified = fi.ModTime().UTC().Format(time.RFC3339)
+		}
+	}
+	return files
+}
+
+// headerLines returns the lines of the header of -header: a line for
+// each of inputs, followed by the time now.
+func headerLines(now time.Time) []string {
+	var lines []string
+	for _, f := range inputs {
+		line := f.Role + ": " + f.Path
+		if f.Modified != "" {
+			line += " (modified " + f.Modified + ")"
+		}
+		lines = append(lines, line)
+	}
+	return append(lines, "generated: "+now.UTC().Format(time.RFC3339))
+}
+
+// writeTextHeader writes the header of -header to w before text output,
+// followed by a blank line. It writes nothing without -header.
+func writeTextHeader(w io.Writer) {
+	if inputs == nil || *format != "text" {
+		return
+	}
+	for _, line := range headerLines(time.Now()) {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w)
+}
+
+// writeCommentHeader writes the header of -header to w as lines
+// beginning with "# ", which readers of CSV and TSV can be told to
+// skip, as by setting the Comment of a csv.Reader. It writes nothing
+// without -header.
+func writeCommentHeader(w io.Writer) {
+	if inputs == nil {
+		return
+	}
+	for _, line := range headerLines(time.Now()) {
+		fmt.Fprintf(w, "# %s\n", line)
+	}
+}
+
+// A stampWriter writes to w, beginning each line with the time it was
+// begun, in RFC 3339 format, so that the warnings of a run with -header
+// say when they were written, as its header does.
+type stampWriter struct {
+	w       io.Writer
+	now     func() time.Time
+	midLine bool // Did the last write end within a line?
+}
+
+func (s *stampWriter) Write(p []byte) (int, error) {
+	var buf bytes.Buffer
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if !s.midLine {
+			buf.WriteString(s.now().UTC().Format(time.RFC3339) + " ")
+		}
+		buf.Write(line)
+		s.midLine = line[len(line)-1] != '\n'
+	}
+	if _, err := s.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHeader(t *testing.T) {
+	defer func(saved []inputFile) { inputs = saved }(inputs)
+
+	dir, err := ioutil.TempDir("", "benchcmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "new.txt")
+	if err := ioutil.WriteFile(path, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2014, 1, 2, 15, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	inputs = newInputs([]string{"-", filepath.Join(dir, "missing.txt"), path})
+	want := []inputFile{
+		{Role: "old", Path: "-"},
+		{Role: "mid", Path: filepath.Join(dir, "missing.txt")},
+		{Role: "new", Path: path, Modified: "2014-01-02T15:04:05Z"},
+	}
+	if !reflect.DeepEqual(want, inputs) {
+		t.Fatalf("newInputs: want %+v have %+v", want, inputs)
+	}
+
+	now := time.Date(2014, 2, 3, 4, 5, 6, 0, time.UTC)
+	wantLines := []string{
+		"old: -",
+		"mid: " + filepath.Join(dir, "missing.txt"),
+		"new: " + path + " (modified 2014-01-02T15:04:05Z)",
+		"generated: 2014-02-03T04:05:06Z",
+	}
+	if have := headerLines(now); !reflect.DeepEqual(wantLines, have) {
+		t.Errorf("headerLines: want %q have %q", wantLines, have)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := renderJSON(buf, nil); err != nil {
+		t.Fatalf("renderJSON failed: %v", err)
+	}
+	var report jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("renderJSON wrote invalid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(want, report.Inputs) {
+		t.Errorf("renderJSON inputs: want %+v have %+v", want, report.Inputs)
+	}
+
+	buf.Reset()
+	if err := renderCSV(buf, nil); err != nil {
+		t.Fatalf("renderCSV failed: %v", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) != 6 || lines[0] != "# old: -" || !strings.HasPrefix(lines[3], "# generated: ") || lines[4] != "benchmark,metric,old,new,delta" {
+		t.Errorf("renderCSV with -header: have\n%s", buf)
+	}
+
+	// Without -header, the formats are unchanged.
+	inputs = nil
+	buf.Reset()
+	renderCSV(buf, nil)
+	if have := buf.String(); have != "benchmark,metric,old,new,delta\n" {
+		t.Errorf("renderCSV without -header: have %q", have)
+	}
+	buf.Reset()
+	writeTextHeader(buf)
+	if buf.Len() != 0 {
+		t.Errorf("writeTextHeader without -header: have %q", buf)
+	}
+}
+
+func TestStampWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	now := time.Date(2014, 2, 3, 4, 5, 6, 0, time.UTC)
+	w := &stampWriter{w: buf, now: func() time.Time { return now }}
+	w.Write([]byte("benchcmp: only in old.txt:\n\tBenchmark"))
+	now = now.Add(time.Second)
+	w.Write([]byte("Gone\n"))
+	w.Write([]byte("\tBenchmarkLost\n"))
+	want := "" +
+		"2014-02-03T04:05:06Z benchcmp: only in old.txt:\n" +
+		"2014-02-03T04:05:06Z \tBenchmarkGone\n" +
+		"2014-02-03T04:05:07Z \tBenchmarkLost\n"
+	if have := buf.String(); have != want {
+		t.Errorf("stampWriter: want %q have %q", want, have)
+	}
+}
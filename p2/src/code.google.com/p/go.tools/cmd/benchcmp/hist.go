@@ -0,0 +1,93 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// histBounds holds the bucket boundaries set by -histbounds.
+var histBounds []float64
+
+// histWidth is the length of the bar drawn by -hist
+// for the fullest bucket.
+const histWidth = 40
+
+// parseBounds parses a comma-separated list of percentages
+// in increasing order, such as -10,-5,0,5,10.
+func parseBounds(s string) ([]float64, error) {
+	var bounds []float64
+	for _, f := range strings.Split(s, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil || math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, fmt.Errorf("invalid bound %q", f)
+		}
+		if len(bounds) > 0 && v <= bounds[len(bounds)-1] {
+			return nil, errors.New("bounds must be in increasing order")
+		}
+		bounds = append(bounds, v)
+	}
+	return bounds, nil
+}
+
+// bucketCounts returns the number of deltas in each of the len(bounds)+1
+// buckets that bounds divide the number line into. Bucket 0 holds the
+// deltas below bounds[0], and bucket i the deltas at least bounds[i-1]
+// and below bounds[i], the last having no upper bound.
+func bucketCounts(deltas, bounds []float64) []int {
+	counts := make([]int, len(bounds)+1)
+	for _, d := range deltas {
+		i := sort.Search(len(bounds), func(i int) bool { return bounds[i] > d })
+		counts[i]++
+	}
+	return counts
+}
+
+// bucketLabel describes bucket i of those divided by bounds.
+func bucketLabel(bounds []float64, i int) string {
+	switch {
+	case i == 0:
+		return fmt.Sprintf("< %g%%", bounds[0])
+	case i == len(bounds):
+		return fmt.Sprintf(">= %g%%", bounds[i-1])
+	}
+	return fmt.Sprintf("%g%% to %g%%", bounds[i-1], bounds[i])
+}
+
+// renderHist writes to out a histogram of the ns/op changes in cmps,
+// bucketed by histBounds.
+func renderHist(out io.Writer, cmps []benchcmp.BenchCmp) error {
+	var deltas []float64
+	for _, cmp := range cmps {
+		if cmp.Measured(benchcmp.NsOp) {
+			deltas = append(deltas, 100*cmp.DeltaNsOp().Float64()-100)
+		}
+	}
+	if len(deltas) == 0 {
+		return nil
+	}
+	counts := bucketCounts(deltas, histBounds)
+	max := 0
+	for _, n := range counts {
+		if n > max {
+			max = n
+		}
+	}
+
+	w := newTableWriter(out, *minWidth, *padding)
+	fmt.Fprintln(w, "ns/op delta\tbenchmarks\t")
+	for i, n := range counts {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", bucketLabel(histBounds, i), n, strings.Repeat("█", n*histWidth/max))
+	}
+	return w.Flush()
+}
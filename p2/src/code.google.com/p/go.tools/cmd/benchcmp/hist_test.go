@@ -0,0 +1,45 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestBucketCounts(t *testing.T) {
+	bounds := []float64{-10, 0, 10}
+	deltas := []float64{-50, -10, -9.5, 0, 0, 3, 10, 25, math.Inf(1)}
+	want := []int{1, 2, 3, 3}
+	if have := bucketCounts(deltas, bounds); !reflect.DeepEqual(want, have) {
+		t.Errorf("bucketCounts: want %v have %v", want, have)
+	}
+	if want, have := []int{0}, bucketCounts(nil, nil); !reflect.DeepEqual(want, have) {
+		t.Errorf("bucketCounts with no bounds: want %v have %v", want, have)
+	}
+
+	labels := []string{"< -10%", "-10% to 0%", "0% to 10%", ">= 10%"}
+	for i, want := range labels {
+		if have := bucketLabel(bounds, i); want != have {
+			t.Errorf("bucketLabel(%d): want %q have %q", i, want, have)
+		}
+	}
+}
+
+func TestParseBounds(t *testing.T) {
+	have, err := parseBounds("-5, 0,2.5")
+	if err != nil {
+		t.Fatalf("parseBounds failed: %v", err)
+	}
+	if want := []float64{-5, 0, 2.5}; !reflect.DeepEqual(want, have) {
+		t.Errorf("parseBounds: want %v have %v", want, have)
+	}
+	for _, in := range []string{"", "1,x", "5,0", "1,1", "1,Inf"} {
+		if _, err := parseBounds(in); err == nil {
+			t.Errorf("parseBounds(%q) should have failed", in)
+		}
+	}
+}
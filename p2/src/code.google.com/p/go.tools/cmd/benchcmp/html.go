@@ -0,0 +1,104 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"math"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// Styles of the HTML report. They are inline, as many email clients
+// drop style sheets.
+const (
+	htmlTableStyle = "border-collapse: collapse; font-family: monospace; margin-bottom: 1em"
+	htmlCellStyle  = "border: 1px solid #ccc; padding: 2px 8px"
+)
+
+// renderHTML writes cmps to w as a static HTML page holding a table per
+// measurement, with the same columns as renderText. Each change is
+// shaded by its size relative to the largest in its table: red for
+// regressions and green for improvements, deeper for larger changes.
+// With -profile-base, regressed benchmarks link to their profiles.
+func renderHTML(w io.Writer, cmps []benchcmp.BenchCmp) error {
+	buf := new(bytes.Buffer)
+	buf.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>benchcmp</title>\n</head>\n<body>\n")
+	for _, s := range tableSections(cmps) {
+		rows := s.rows(cmps)
+		if len(rows) == 0 {
+			continue
+		}
+		sampled := hasPValues(cmps, s)
+		dc := s.changeColumn()
+		fmt.Fprintf(buf, "<table style=\"%s\">\n", htmlTableStyle)
+		writeHTMLRow(buf, "th", s.header(sampled), -1, "", "")
+		scale := s.barScale(rows)
+		for _, cmp := range rows {
+			cells := s.cells(cmp, sampled)
+			var bg string
+			if cells[dc] != "~" {
+				bg = shade(s.worsening(s.delta(cmp)), scale)
+			}
+			writeHTMLRow(buf, "td", cells, dc, bg, s.profileLink(cmp))
+		}
+		if d, n := s.geoMean(cmps); *showGeoMean && n > 0 {
+			cells := make([]string, len(s.header(sampled)))
+			cells[0] = geoMeanLabel(n)
+			cells[dc] = s.show(d)
+			writeHTMLRow(buf, "td", cells, dc, shade(s.worsening(d), scale), "")
+		}
+		buf.WriteString("</table>\n")
+	}
+	buf.WriteString("</body>\n</html>\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeHTMLRow writes one row of an HTML table to buf, escaping the
+// cells, each an element named tag. All but the first cell are aligned
+// right, cell dc is given the background color bg, if any, and the
+// first cell links to href, if any.
+func writeHTMLRow(buf *bytes.Buffer, tag string, cells []string, dc int, bg, href string) {
+	buf.WriteString("<tr>")
+	for i, c := range cells {
+		style := htmlCellStyle
+		if i > 0 {
+			style += "; text-align: right"
+		}
+		if i == dc && bg != "" {
+			style += "; background-color: " + bg
+		}
+		c = html.EscapeString(c)
+		if i == 0 && href != "" {
+			c = fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(href), c)
+		}
+		fmt.Fprintf(buf, "<%s style=\"%s\">%s</%s>", tag, style, c, tag)
+	}
+	buf.WriteString("</tr>\n")
+}
+
+// shade returns the background color of a change that is a worsening
+// of w percent, relative to max, the largest in its table: a red, for a
+// regression, or green, for an improvement, that deepens from white
+// with the magnitude of w. A change from zero is shaded fully. No change
+// is not shaded, and shade returns "".
+func shade(w, max float64) string {
+	f := 1.0
+	if !math.IsInf(w, 0) {
+		if w == 0 || max == 0 {
+			return ""
+		}
+		f = math.Min(math.Abs(w)/max, 1)
+	}
+	light := 255 - int(f*155+0.5)
+	if w > 0 {
+		return fmt.Sprintf("#ff%02x%02x", light, light)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", light, 255-int(f*55+0.5), light)
+}
@@ -0,0 +1,66 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestShade(t *testing.T) {
+	for _, tt := range []struct {
+		w, max float64
+		want   string
+	}{
+		{0, 20, ""},
+		{5, 0, ""},
+		{20, 20, "#ff6464"},
+		{10, 20, "#ffb1b1"},
+		{-20, 20, "#64c864"},
+		{-10, 20, "#b1e3b1"},
+		{math.Inf(1), 20, "#ff6464"},
+		{math.Inf(-1), 0, "#64c864"},
+	} {
+		if have := shade(tt.w, tt.max); have != tt.want {
+			t.Errorf("shade(%v, %v): want %q have %q", tt.w, tt.max, tt.want, have)
+		}
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkSlower<T>", NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkSlower<T>", NsOp: 120, Measured: benchcmp.NsOp},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkFaster", NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkFaster", NsOp: 90, Measured: benchcmp.NsOp},
+		},
+	}
+	buf := new(bytes.Buffer)
+	if err := renderHTML(buf, cmps); err != nil {
+		t.Fatalf("renderHTML failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"BenchmarkSlower&lt;T&gt;</td>",
+		"background-color: #ff6464\">+20.00%</td>",
+		"background-color: #b1e3b1\">-10.00%</td>",
+		"</html>\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("renderHTML: output lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<script") || strings.Contains(out, "<style") {
+		t.Errorf("renderHTML: output is not static and inline:\n%s", out)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// influxTime is the time of the points written by -format=influx, as
+// set by -timestamp, or the zero time to use the time of writing.
+var influxTime time.Time
+
+// influxEscaper escapes a tag value of the InfluxDB line protocol.
+var influxEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`, " ", `\ `)
+
+// parseTimestamp parses the argument of -timestamp: a time in RFC 3339
+// format, or a number of seconds since the Unix epoch.
+func parseTimestamp(s string) (time.Time, error) {
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// renderInflux writes cmps to w in the InfluxDB line protocol: a point
+// of the benchmark measurement for each benchmark, tagged by name, with
+// a field for each measurement of the new run. Allocations and bytes
+// are integer fields and the others are floats, so that each field
+// keeps one type across runs.
+func renderInflux(w io.Writer, cmps []benchcmp.BenchCmp) error {
+	ts := influxTime
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	bw := bufio.NewWriter(w)
+	all := selectSections(allSections(cmps))
+	for _, cmp := range cmps {
+		var fields []string
+		for _, s := range all {
+			if !s.measured(cmp) {
+				continue
+			}
+			v := s.delta(cmp).After
+			value := formatFloat(v)
+			if s.flag == benchcmp.AllocsOp || s.flag == benchcmp.BOp {
+				value = strconv.FormatUint(uint64(v), 10) + "i"
+			}
+			fields = append(fields, snakeName(s.label)+"="+value)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		fmt.Fprintf(bw, "benchmark,name=%s %s %d\n", influxEscaper.Replace(cmp.Name()), strings.Join(fields, ","), ts.UnixNano())
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,61 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestRenderInflux(t *testing.T) {
+	defer func(saved time.Time) { influxTime = saved }(influxTime)
+	influxTime = time.Unix(1400000000, 5)
+
+	all := benchcmp.NsOp | benchcmp.AllocsOp | benchcmp.BOp
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkEncrypt/size=1k key", NsOp: 19.6, AllocsOp: 5, BOp: 48, Measured: all},
+			After:  &benchcmp.Bench{Name: "BenchmarkEncrypt/size=1k key", NsOp: 12.3, AllocsOp: 4, BOp: 32, Measured: all},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkDecrypt", NsOp: 517, Extra: map[string]float64{"items/op": 3}, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkDecrypt", NsOp: 617, Extra: map[string]float64{"items/op": 4}, Measured: benchcmp.NsOp},
+		},
+	}
+	buf := new(bytes.Buffer)
+	if err := renderInflux(buf, cmps); err != nil {
+		t.Fatalf("renderInflux failed: %v", err)
+	}
+	want := `benchmark,name=BenchmarkEncrypt/size\=1k\ key ns_op=12.3,allocs=4i,bytes=32i 1400000000000000005
+benchmark,name=BenchmarkDecrypt ns_op=617,items_op=4 1400000000000000005
+`
+	if have := buf.String(); want != have {
+		t.Errorf("renderInflux: want\n%s\nhave\n%s", want, have)
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want int64
+	}{
+		{"1400000000", 1400000000},
+		{"2014-05-13T16:53:20Z", 1400000000},
+		{"2014-05-13T18:53:20+02:00", 1400000000},
+	} {
+		have, err := parseTimestamp(tt.in)
+		if err != nil || have.Unix() != tt.want {
+			t.Errorf("parseTimestamp(%q): want %d have %d, %v", tt.in, tt.want, have.Unix(), err)
+		}
+	}
+	for _, in := range []string{"", "now", "2014-05-13"} {
+		if _, err := parseTimestamp(in); err == nil {
+			t.Errorf("parseTimestamp(%q) should have failed", in)
+		}
+	}
+}
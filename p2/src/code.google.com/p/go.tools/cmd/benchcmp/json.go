@@ -0,0 +1,228 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// jsonVersion is the version of the schema of -format=json, written as
+// the version of each report. It must be incremented whenever the
+// schema changes in a way that could break its readers, such as by
+// removing or renaming a field; adding a field does not.
+//
+// Version 1 wrapped the array of comparisons of earlier output,
+// which parseJSON still reads, in a jsonReport.
+const jsonVersion = 1
+
+// jsonReport is the JSON representation of a comparison: the time
+// it was generated, in RFC 3339 format, the files compared, with
+// -header, and each of its benchmarks.
+type jsonReport struct {
+	Version    int            `json:"version"`
+	Generated  string         `json:"generated"`
+	Inputs     []inputFile    `json:"inputs,omitempty"`
+	Benchmarks []jsonBenchCmp `json:"benchmarks"`
+}
+
+func newJSONReport(cmps []benchcmp.BenchCmp) jsonReport {
+	r := jsonReport{
+		Version:    jsonVersion,
+		Generated:  time.Now().UTC().Format(time.RFC3339),
+		Inputs:     inputs,
+		Benchmarks: make([]jsonBenchCmp, len(cmps)),
+	}
+	for i, cmp := range cmps {
+		r.Benchmarks[i] = newJSONBenchCmp(cmp)
+	}
+	return r
+}
+
+// jsonBenchCmp is the JSON representation of a BenchCmp.
+// Measurements that were not recorded by both runs are omitted.
+type jsonBenchCmp struct {
+	Name     string       `json:"name"`
+	NsOp     *jsonMeasure `json:"ns_op,omitempty"`
+	MbS      *jsonMeasure `json:"mb_s,omitempty"`
+	AllocsOp *jsonMeasure `json:"allocs_op,omitempty"`
+	BOp      *jsonMeasure `json:"b_op,omitempty"`
+
+	// Extra holds any extra measurements, keyed by unit.
+	Extra map[string]*jsonMeasure `json:"extra,omitempty"`
+}
+
+// jsonMeasure is a single measurement before and after, along with
+// the computed change. At most one of Percent and Speedup is set.
+// A change that cannot be represented as a finite number
+// (because Before is zero) is omitted. P is set when both sides
+// have enough samples to test the change for significance.
+type jsonMeasure struct {
+	Before  float64  `json:"before"`
+	After   float64  `json:"after"`
+	Percent *float64 `json:"delta_percent,omitempty"`
+	Speedup *float64 `json:"speedup,omitempty"`
+	P       *float64 `json:"p,omitempty"`
+}
+
+func newJSONBenchCmp(cmp benchcmp.BenchCmp) jsonBenchCmp {
+	j := jsonBenchCmp{Name: cmp.Name()}
+	if cmp.Measured(benchcmp.NsOp) {
+		j.NsOp = percentMeasure(cmp, benchcmp.Unit(benchcmp.NsOp), cmp.DeltaNsOp())
+	}
+	if cmp.Measured(benchcmp.MbS) {
+		d := cmp.DeltaMbS()
+		j.MbS = &jsonMeasure{Before: d.Before, After: d.After, Speedup: finite(d.Float64())}
+		if p, ok := pValue(cmp, benchcmp.Unit(benchcmp.MbS)); ok {
+			j.MbS.P = &p
+		}
+	}
+	if cmp.Measured(benchcmp.AllocsOp) {
+		j.AllocsOp = percentMeasure(cmp, benchcmp.Unit(benchcmp.AllocsOp), cmp.DeltaAllocsOp())
+	}
+	if cmp.Measured(benchcmp.BOp) {
+		j.BOp = percentMeasure(cmp, benchcmp.Unit(benchcmp.BOp), cmp.DeltaBOp())
+	}
+	for unit := range cmp.Before.Extra {
+		if !cmp.MeasuredExtra(unit) {
+			continue
+		}
+		if j.Extra == nil {
+			j.Extra = make(map[string]*jsonMeasure)
+		}
+		j.Extra[unit] = percentMeasure(cmp, unit, cmp.DeltaExtra(unit))
+	}
+	return j
+}
+
+func percentMeasure(cmp benchcmp.BenchCmp, unit string, d benchcmp.Delta) *jsonMeasure {
+	m := &jsonMeasure{Before: d.Before, After: d.After, Percent: finite(100*d.Float64() - 100)}
+	if p, ok := pValue(cmp, unit); ok {
+		m.P = &p
+	}
+	return m
+}
+
+// finite returns a pointer to f, or nil if f is infinite or NaN,
+// neither of which can be encoded as JSON.
+func finite(f float64) *float64 {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil
+	}
+	return &f
+}
+
+// parseJSON parses the output of renderJSON from br into a BenchSet
+// holding the After side of each comparison, so that a comparison
+// saved with -format=json can serve as the baseline of another.
+// The bare array of comparisons written before version 1 is read too.
+func parseJSON(br *bufio.Reader) (benchcmp.BenchSet, error) {
+	var in []jsonBenchCmp
+	if firstByte(br) == '[' {
+		if err := json.NewDecoder(br).Decode(&in); err != nil {
+			return nil, err
+		}
+	} else {
+		var report jsonReport
+		if err := json.NewDecoder(br).Decode(&report); err != nil {
+			return nil, err
+		}
+		if report.Version > jsonVersion {
+			return nil, fmt.Errorf("unsupported version %d of -format=json; this benchcmp reads up to version %d", report.Version, jsonVersion)
+		}
+		in = report.Benchmarks
+	}
+	bb := make(benchcmp.BenchSet)
+	for i, j := range in {
+		b := &benchcmp.Bench{Name: j.Name}
+		if j.NsOp != nil {
+			b.NsOp = j.NsOp.After
+			b.Measured |= benchcmp.NsOp
+		}
+		if j.MbS != nil {
+			b.MbS = j.MbS.After
+			b.Measured |= benchcmp.MbS
+		}
+		if j.AllocsOp != nil {
+			b.AllocsOp = uint64(j.AllocsOp.After)
+			b.Measured |= benchcmp.AllocsOp
+		}
+		if j.BOp != nil {
+			b.BOp = uint64(j.BOp.After)
+			b.Measured |= benchcmp.BOp
+		}
+		for unit, m := range j.Extra {
+			if b.Extra == nil {
+				b.Extra = make(map[string]float64)
+			}
+			b.Extra[unit] = m.After
+		}
+		bb.Add(b, i)
+	}
+	return bb, nil
+}
+
+// isJSON reports whether the input buffered by br begins, after any
+// white space, with a report written by -format=json, rather than with
+// go test output: either a JSON object whose first key is "version",
+// unlike the events of go test -json, or an array, as written before
+// the schema had versions.
+func isJSON(br *bufio.Reader) bool {
+	switch firstByte(br) {
+	case '[':
+		return true
+	case '{':
+		// Peek past the white space and the brace, however much
+		// space there is, for the first key.
+		n, _ := skipSpace(br)
+		buf, _ := br.Peek(n + 1 + 256)
+		rest := bytes.TrimLeft(buf[n+1:], " \t\r\n")
+		return bytes.HasPrefix(rest, []byte(`"version"`))
+	}
+	return false
+}
+
+// firstByte returns the first byte other than white space in the input
+// buffered by br, without consuming it, or 0 if there is none.
+func firstByte(br *bufio.Reader) byte {
+	_, c := skipSpace(br)
+	return c
+}
+
+// skipSpace returns the number of bytes of white space that begin the
+// input buffered by br, without consuming them, and the byte after
+// them, or 0 if there is none.
+func skipSpace(br *bufio.Reader) (int, byte) {
+	for n := 1; ; n++ {
+		buf, err := br.Peek(n)
+		if err != nil {
+			return n - 1, 0
+		}
+		switch c := buf[n-1]; c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		default:
+			return n - 1, c
+		}
+	}
+}
+
+// renderJSON writes cmps to w as a jsonReport listing them in order.
+func renderJSON(w io.Writer, cmps []benchcmp.BenchCmp) error {
+	b, err := json.MarshalIndent(newJSONReport(cmps), "", "\t")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return err
+}
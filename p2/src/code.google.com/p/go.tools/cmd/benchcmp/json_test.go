@@ -0,0 +1,138 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestRenderJSON(t *testing.T) {
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkTime", NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkTime", NsOp: 150, Measured: benchcmp.NsOp},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkAll", NsOp: 10, MbS: 2, BOp: 0, AllocsOp: 4, Measured: benchcmp.NsOp | benchcmp.MbS | benchcmp.BOp | benchcmp.AllocsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkAll", NsOp: 5, MbS: 4, BOp: 8, AllocsOp: 2, Measured: benchcmp.NsOp | benchcmp.MbS | benchcmp.BOp | benchcmp.AllocsOp},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := renderJSON(buf, cmps); err != nil {
+		t.Fatalf("renderJSON failed: %v", err)
+	}
+
+	var report struct {
+		Version    int
+		Generated  string
+		Benchmarks []map[string]interface{}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("renderJSON produced invalid JSON: %v\n%s", err, buf)
+	}
+	if report.Version != jsonVersion {
+		t.Errorf("renderJSON: want version %d have %d", jsonVersion, report.Version)
+	}
+	if _, err := time.Parse(time.RFC3339, report.Generated); err != nil {
+		t.Errorf("renderJSON: invalid generated time: %v", err)
+	}
+	have := report.Benchmarks
+	want := []map[string]interface{}{
+		{
+			"name":  "BenchmarkTime",
+			"ns_op": map[string]interface{}{"before": 100.0, "after": 150.0, "delta_percent": 50.0},
+		},
+		{
+			"name":      "BenchmarkAll",
+			"ns_op":     map[string]interface{}{"before": 10.0, "after": 5.0, "delta_percent": -50.0},
+			"mb_s":      map[string]interface{}{"before": 2.0, "after": 4.0, "speedup": 2.0},
+			"allocs_op": map[string]interface{}{"before": 4.0, "after": 2.0, "delta_percent": -50.0},
+			"b_op":      map[string]interface{}{"before": 0.0, "after": 8.0},
+		},
+	}
+	if !reflect.DeepEqual(want, have) {
+		t.Errorf("renderJSON incorrect output:\nwant %v\nhave %v", want, have)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkZ", NsOp: 100, AllocsOp: 4, Measured: benchcmp.NsOp | benchcmp.AllocsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkZ", NsOp: 150, AllocsOp: 3, Measured: benchcmp.NsOp | benchcmp.AllocsOp},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkA", MbS: 2, BOp: 0, Measured: benchcmp.MbS | benchcmp.BOp, Extra: map[string]float64{"items/op": 4}},
+			After:  &benchcmp.Bench{Name: "BenchmarkA", MbS: 4, BOp: 8, Measured: benchcmp.MbS | benchcmp.BOp, Extra: map[string]float64{"items/op": 5}},
+		},
+	}
+	buf := new(bytes.Buffer)
+	if err := renderJSON(buf, cmps); err != nil {
+		t.Fatalf("renderJSON failed: %v", err)
+	}
+
+	br := bufio.NewReader(io.MultiReader(strings.NewReader("\n  "), buf))
+	if !isJSON(br) {
+		t.Fatalf("isJSON = false for renderJSON output")
+	}
+	bb, err := parseJSON(br)
+	if err != nil {
+		t.Fatalf("parseJSON failed: %v", err)
+	}
+	if len(bb) != 2 || len(bb["BenchmarkZ"]) != 1 || len(bb["BenchmarkA"]) != 1 {
+		t.Fatalf("parseJSON: wrong benchmarks %v", bb)
+	}
+	for _, cmp := range cmps {
+		want, have := cmp.After.String(), bb[cmp.Name()][0].String()
+		if want != have {
+			t.Errorf("parseJSON: want %s have %s", want, have)
+		}
+	}
+
+	// Parse order is preserved.
+	sorted := []benchcmp.BenchCmp{{Before: bb["BenchmarkA"][0]}, {Before: bb["BenchmarkZ"][0]}}
+	sort.Sort(benchcmp.ByParseOrder(sorted))
+	if sorted[0].Name() != "BenchmarkZ" {
+		t.Errorf("parseJSON did not preserve the order of benchmarks")
+	}
+
+	// Output from before the schema had versions is read as well.
+	legacy := `[{"name": "BenchmarkA", "ns_op": {"before": 2, "after": 3}}]`
+	bb, err = parseJSON(bufio.NewReader(strings.NewReader(legacy)))
+	if err != nil || len(bb) != 1 || bb["BenchmarkA"][0].NsOp != 3 {
+		t.Errorf("parseJSON of an unversioned array: have %v, %v", bb, err)
+	}
+	future := `{"version": 99, "benchmarks": []}`
+	if !isJSON(bufio.NewReader(strings.NewReader(future))) {
+		t.Errorf("isJSON = false for a report of a later version")
+	}
+	if _, err := parseJSON(bufio.NewReader(strings.NewReader(future))); err == nil {
+		t.Errorf("parseJSON of a later version should have failed")
+	}
+
+	// Leading white space, even more than the first key is looked
+	// for in, is skipped.
+	space := strings.Repeat(" \n", 200)
+	if !isJSON(bufio.NewReader(strings.NewReader(space + future))) {
+		t.Errorf("isJSON = false for a report after long white space")
+	}
+
+	for _, in := range []string{"BenchmarkA\t100\t5 ns/op\n", "", "   ", `{"Action":"output"}`, "{", space + "{", space + "{" + space} {
+		if isJSON(bufio.NewReader(strings.NewReader(in))) {
+			t.Errorf("isJSON(%q) = true, want false", in)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// junitSuite is a JUnit XML test suite with one test case per benchmark.
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// renderJUnit writes cmps to w as a JUnit XML test suite, in which each
+// benchmark is a test case that fails if any of its measurements
+// regressed by more than its threshold percent.
+func renderJUnit(w io.Writer, cmps []benchcmp.BenchCmp, threshold thresholds) error {
+	suite := junitSuite{Name: "benchcmp", Tests: len(cmps)}
+	for _, cmp := range cmps {
+		c := junitCase{Name: cmp.Name(), Classname: "benchcmp"}
+		if regs := findRegressions([]benchcmp.BenchCmp{cmp}, threshold); len(regs) > 0 {
+			var msgs []string
+			for _, r := range regs {
+				msgs = append(msgs, r.String())
+			}
+			c.Failure = &junitFailure{
+				Message: strings.Join(msgs, "; "),
+				Type:    "regression",
+				Text:    strings.Join(msgs, "\n"),
+			}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	b, err := xml.MarshalIndent(suite, "", "\t")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,42 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestRenderJUnit(t *testing.T) {
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkSlower", NsOp: 100, AllocsOp: 1, Measured: benchcmp.NsOp | benchcmp.AllocsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkSlower", NsOp: 110, AllocsOp: 2, Measured: benchcmp.NsOp | benchcmp.AllocsOp},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkSteady<T>", NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkSteady<T>", NsOp: 102, Measured: benchcmp.NsOp},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := renderJUnit(buf, cmps, thresholds{"": 5}); err != nil {
+		t.Fatalf("renderJUnit failed: %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="benchcmp" tests="2" failures="1">
+	<testcase name="BenchmarkSlower" classname="benchcmp">
+		<failure message="BenchmarkSlower ns/op regressed: +10.00%; BenchmarkSlower allocs/op regressed: +100.00%" type="regression">BenchmarkSlower ns/op regressed: +10.00%&#xA;BenchmarkSlower allocs/op regressed: +100.00%</failure>
+	</testcase>
+	<testcase name="BenchmarkSteady&lt;T&gt;" classname="benchcmp"></testcase>
+</testsuite>
+`
+	if have := buf.String(); want != have {
+		t.Errorf("renderJUnit incorrect output:\nwant %s\nhave %s", want, have)
+	}
+}
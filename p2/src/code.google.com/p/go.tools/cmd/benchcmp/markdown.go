@@ -0,0 +1,69 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// markdownEscaper escapes text for use in a cell of a Markdown table.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`)
+
+// renderMarkdown writes cmps to w as a set of GitHub-flavored Markdown
+// tables, one per measurement, with the same columns as renderText.
+// With -profile-base, regressed benchmarks link to their profiles.
+func renderMarkdown(w io.Writer, cmps []benchcmp.BenchCmp) error {
+	buf := new(bytes.Buffer)
+	var shown bool // Has any table been written yet?
+	for _, s := range tableSections(cmps) {
+		rows := s.rows(cmps)
+		if len(rows) == 0 {
+			continue
+		}
+		if shown {
+			buf.WriteString("\n")
+		}
+		shown = true
+		sampled := hasPValues(cmps, s)
+		header := s.header(sampled)
+		writeMarkdownRow(buf, header)
+		align := make([]string, len(header))
+		align[0] = "---"
+		for i := 1; i < len(align); i++ {
+			align[i] = "---:"
+		}
+		writeMarkdownRow(buf, align)
+		for _, cmp := range rows {
+			cells := s.cells(cmp, sampled)
+			if href := s.profileLink(cmp); href != "" {
+				cells[0] = fmt.Sprintf("[%s](%s)", cells[0], href)
+			}
+			writeMarkdownRow(buf, cells)
+		}
+		if d, n := s.geoMean(cmps); *showGeoMean && n > 0 {
+			cells := make([]string, len(header))
+			cells[0] = geoMeanLabel(n)
+			cells[s.changeColumn()] = s.show(d)
+			writeMarkdownRow(buf, cells)
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeMarkdownRow writes one row of a Markdown table to buf,
+// escaping the cells.
+func writeMarkdownRow(buf *bytes.Buffer, cells []string) {
+	buf.WriteString("|")
+	for _, c := range cells {
+		fmt.Fprintf(buf, " %s |", markdownEscaper.Replace(c))
+	}
+	buf.WriteString("\n")
+}
@@ -0,0 +1,58 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkEncrypt", NsOp: 19.6, MbS: 817.77, Measured: benchcmp.NsOp | benchcmp.MbS},
+			After:  &benchcmp.Bench{Name: "BenchmarkEncrypt", NsOp: 17.6, MbS: 917.77, Measured: benchcmp.NsOp | benchcmp.MbS},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkPipe|Line", NsOp: 517, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkPipe|Line", NsOp: 617, Measured: benchcmp.NsOp},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := renderMarkdown(buf, cmps); err != nil {
+		t.Fatalf("renderMarkdown failed: %v", err)
+	}
+
+	want := `| benchmark | old ns/op | new ns/op | delta |
+| --- | ---: | ---: | ---: |
+| BenchmarkEncrypt | 19.6 | 17.6 | -10.20% |
+| BenchmarkPipe\|Line | 517 | 617 | +19.34% |
+
+| benchmark | old MB/s | new MB/s | speedup |
+| --- | ---: | ---: | ---: |
+| BenchmarkEncrypt | 817.77 | 917.77 | 1.12x |
+`
+	if have := buf.String(); want != have {
+		t.Errorf("renderMarkdown incorrect output:\nwant %q\nhave %q", want, have)
+	}
+}
+
+func TestMarkdownEscaper(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{in: "BenchmarkPlain", want: "BenchmarkPlain"},
+		{in: "BenchmarkA|B", want: `BenchmarkA\|B`},
+		{in: `BenchmarkA\|B`, want: `BenchmarkA\\\|B`},
+	}
+	for _, tt := range cases {
+		if have := markdownEscaper.Replace(tt.in); tt.want != have {
+			t.Errorf("markdownEscaper.Replace(%q): want %q have %q", tt.in, tt.want, have)
+		}
+	}
+}
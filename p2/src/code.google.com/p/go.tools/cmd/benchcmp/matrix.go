@@ -0,0 +1,73 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// A cpuMatrix holds the ns/op of the benchmarks of a single run at
+// each GOMAXPROCS, as run by go test -cpu, for -matrix=cpu.
+type cpuMatrix struct {
+	names []string                   // base names of the benchmarks, sorted
+	procs []int                      // distinct GOMAXPROCS values, increasing
+	ns    map[string]map[int]float64 // ns/op by base name and GOMAXPROCS
+}
+
+// pivotCPU returns the matrix of the benchmarks in bb selected by
+// -filter, split by benchcmp.SplitName into base name and GOMAXPROCS.
+// Each benchmark is expected to have been run once, as after
+// benchcmp.MergeSamples; those that did not measure ns/op are left out.
+func pivotCPU(bb benchcmp.BenchSet) cpuMatrix {
+	m := cpuMatrix{ns: make(map[string]map[int]float64)}
+	seen := make(map[int]bool)
+	for name, runs := range bb {
+		b := runs[0]
+		if !selects(name) || b.Measured&benchcmp.NsOp == 0 {
+			continue
+		}
+		base, procs := benchcmp.SplitName(name)
+		if m.ns[base] == nil {
+			m.ns[base] = make(map[int]float64)
+			m.names = append(m.names, base)
+		}
+		m.ns[base][procs] = b.NsOp
+		if !seen[procs] {
+			seen[procs] = true
+			m.procs = append(m.procs, procs)
+		}
+	}
+	sort.Strings(m.names)
+	sort.Ints(m.procs)
+	return m
+}
+
+// renderMatrix writes m to w as a table with a row for each benchmark
+// and a column of ns/op for each GOMAXPROCS. A benchmark not run at a
+// GOMAXPROCS has a blank cell.
+func renderMatrix(w io.Writer, m cpuMatrix) error {
+	tw := newTableWriter(w, *minWidth, *padding)
+	header := []string{"benchmark"}
+	for _, p := range m.procs {
+		header = append(header, fmt.Sprintf("cpu=%d", p))
+	}
+	writeRow(tw, header)
+	for _, name := range m.names {
+		cells := []string{name}
+		for _, p := range m.procs {
+			cell := ""
+			if ns, ok := m.ns[name][p]; ok {
+				cell = formatNsOp(ns)
+			}
+			cells = append(cells, cell)
+		}
+		writeRow(tw, cells)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,57 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestPivotCPU(t *testing.T) {
+	defer func(saved *regexp.Regexp) { filterRE = saved }(filterRE)
+	filterRE = nil
+
+	// As written by go test -bench=. -cpu=1,2,4, with BenchmarkSerial
+	// skipped at 4.
+	const log = `BenchmarkParallel	1000000	1200 ns/op
+BenchmarkParallel-2	2000000	620 ns/op
+BenchmarkParallel-4	5000000	330 ns/op
+BenchmarkSerial	1000000	1000 ns/op
+BenchmarkSerial-2	1000000	1010 ns/op
+BenchmarkMem-4	1000000	8 B/op
+`
+	l, err := benchcmp.ParseLog(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	m := pivotCPU(benchcmp.MergeSamples(l.Benchmarks))
+	want := cpuMatrix{
+		names: []string{"BenchmarkParallel", "BenchmarkSerial"},
+		procs: []int{1, 2, 4},
+		ns: map[string]map[int]float64{
+			"BenchmarkParallel": {1: 1200, 2: 620, 4: 330},
+			"BenchmarkSerial":   {1: 1000, 2: 1010},
+		},
+	}
+	if !reflect.DeepEqual(want, m) {
+		t.Errorf("pivotCPU: want %+v have %+v", want, m)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := renderMatrix(buf, m); err != nil {
+		t.Fatalf("renderMatrix failed: %v", err)
+	}
+	wantText := "benchmark             cpu=1     cpu=2     cpu=4     \n" +
+		"BenchmarkParallel     1200      620       330       \n" +
+		"BenchmarkSerial       1000      1010                \n"
+	if have := buf.String(); have != wantText {
+		t.Errorf("renderMatrix: want\n%q\nhave\n%q", wantText, have)
+	}
+}
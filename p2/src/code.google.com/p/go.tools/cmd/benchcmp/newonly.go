@@ -0,0 +1,84 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// newBenchmarks returns the benchmarks selected by -filter that are in
+// the file at newPath but not in the one at oldPath, sorted by name, for
+// -new-only. Benchmarks renamed by -rename or matched by -fuzzy are not
+// new, and nor are those skipped in the old file. The old file may be
+// empty, but it returns an error if there are no new benchmarks.
+func newBenchmarks(oldPath, newPath string) ([]*benchcmp.Bench, error) {
+	logs := parseFiles(oldPath, newPath)
+	oldLog, newLog := logs[0], logs[1]
+	if err := checkEmpty([]string{newPath}, logs[1:]); err != nil {
+		return nil, err
+	}
+	before := benchcmp.Rename(oldLog.Benchmarks, renames)
+	after := newLog.Benchmarks
+	if *fuzzy {
+		before = fuzzyRename(before, after)
+	}
+	_, onlyAfter := benchcmp.Unmatched(before, after)
+	onlyAfter = reportSkipped(oldPath, onlyAfter, oldLog.Skipped)
+
+	var added []*benchcmp.Bench
+	for _, name := range onlyAfter {
+		if selects(name) {
+			added = append(added, after[name][0])
+		}
+	}
+	if len(added) == 0 {
+		return nil, fmt.Errorf("no benchmarks only in %s", newPath)
+	}
+	return added, nil
+}
+
+// renderNewOnly writes bb to w as a table of their measured values, with
+// a column for each measurement selected by -metric that any of them
+// recorded. A benchmark that did not record a measurement has a blank
+// cell.
+func renderNewOnly(w io.Writer, bb []*benchcmp.Bench) error {
+	// Compare each benchmark with itself, so that the sections
+	// of the comparison tables describe its measurements.
+	self := make([]benchcmp.BenchCmp, len(bb))
+	for i, b := range bb {
+		self[i] = benchcmp.BenchCmp{Before: b, After: b}
+	}
+	var cols []section
+	for _, s := range selectSections(allSections(self)) {
+		for _, cmp := range self {
+			if s.measured(cmp) {
+				cols = append(cols, s)
+				break
+			}
+		}
+	}
+
+	tw := newTableWriter(w, *minWidth, *padding)
+	header := []string{"new benchmark"}
+	for _, s := range cols {
+		header = append(header, s.unit)
+	}
+	writeRow(tw, header)
+	for _, cmp := range self {
+		cells := []string{cmp.Name()}
+		for _, s := range cols {
+			cell := ""
+			if s.measured(cmp) {
+				cell = s.sampleValue(cmp.After)
+			}
+			cells = append(cells, cell)
+		}
+		writeRow(tw, cells)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,57 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestNewBenchmarks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchcmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	oldPath, newPath := filepath.Join(dir, "old.txt"), filepath.Join(dir, "new.txt")
+	ioutil.WriteFile(oldPath, []byte("BenchmarkKept\t1000\t100 ns/op\n"), 0666)
+	ioutil.WriteFile(newPath, []byte("BenchmarkKept\t1000\t90 ns/op\nBenchmarkAdded\t500\t250 ns/op\t16 B/op\t1 allocs/op\n"), 0666)
+
+	bb, err := newBenchmarks(oldPath, newPath)
+	if err != nil {
+		t.Fatalf("newBenchmarks failed: %v", err)
+	}
+	if len(bb) != 1 || bb[0].Name != "BenchmarkAdded" {
+		t.Fatalf("newBenchmarks: want BenchmarkAdded have %v", bb)
+	}
+
+	ioutil.WriteFile(newPath, []byte("BenchmarkKept\t1000\t90 ns/op\n"), 0666)
+	if _, err := newBenchmarks(oldPath, newPath); err == nil {
+		t.Errorf("newBenchmarks with nothing new: want error")
+	}
+}
+
+func TestRenderNewOnly(t *testing.T) {
+	bb := []*benchcmp.Bench{
+		{Name: "BenchmarkAdded", NsOp: 250, BOp: 16, AllocsOp: 1, Measured: benchcmp.NsOp | benchcmp.BOp | benchcmp.AllocsOp},
+		{Name: "BenchmarkTimed", NsOp: 1500, Measured: benchcmp.NsOp},
+	}
+	buf := new(bytes.Buffer)
+	if err := renderNewOnly(buf, bb); err != nil {
+		t.Fatalf("renderNewOnly failed: %v", err)
+	}
+	want := "" +
+		"new benchmark      ns/op     allocs/op     B/op     \n" +
+		"BenchmarkAdded     250       1             16       \n" +
+		"BenchmarkTimed     1500                             \n"
+	if have := buf.String(); have != want {
+		t.Errorf("renderNewOnly: want\n%q\nhave\n%q", want, have)
+	}
+}
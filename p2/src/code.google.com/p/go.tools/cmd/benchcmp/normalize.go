@@ -0,0 +1,86 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// normalize returns the benchmarks of bb, read from path, with their
+// ns/op, MB/s, and custom metrics divided by those of the benchmark
+// named base in the same run, so that each is a ratio to it that does
+// not depend on the speed of the machine. The base name may omit the
+// GOMAXPROCS suffix. Allocations and bytes, which do not depend on the
+// machine, are left alone, as are metrics the base did not record.
+func normalize(path string, bb benchcmp.BenchSet, base string) (benchcmp.BenchSet, error) {
+	ref := baseline(bb, base)
+	if ref == nil {
+		return nil, fmt.Errorf("-normalize: no benchmark %s in %s", base, path)
+	}
+	if ref.Measured&benchcmp.NsOp == 0 || ref.NsOp == 0 {
+		return nil, fmt.Errorf("-normalize: %s in %s did not measure ns/op", base, path)
+	}
+	// Record the divisors first, since ref is itself normalized.
+	scale := map[string]float64{"ns/op": ref.NsOp}
+	if ref.Measured&benchcmp.MbS != 0 && ref.MbS != 0 {
+		scale["MB/s"] = ref.MbS
+	}
+	for unit, v := range ref.Extra {
+		if v != 0 {
+			scale[unit] = v
+		}
+	}
+
+	norm := make(benchcmp.BenchSet)
+	for name, runs := range bb {
+		for _, b := range runs {
+			n := *b
+			n.NsOp /= scale["ns/op"]
+			if d, ok := scale["MB/s"]; ok {
+				n.MbS /= d
+			}
+			if b.Extra != nil {
+				n.Extra = make(map[string]float64)
+				for unit, v := range b.Extra {
+					if d, ok := scale[unit]; ok {
+						v /= d
+					}
+					n.Extra[unit] = v
+				}
+			}
+			if b.Samples != nil {
+				n.Samples = make(map[string][]float64)
+				for unit, x := range b.Samples {
+					if d, ok := scale[unit]; ok {
+						s := make([]float64, len(x))
+						for i, v := range x {
+							s[i] = v / d
+						}
+						x = s
+					}
+					n.Samples[unit] = x
+				}
+			}
+			norm[name] = append(norm[name], &n)
+		}
+	}
+	return norm, nil
+}
+
+// baseline returns the benchmark of bb named name, with or without its
+// GOMAXPROCS suffix, or nil if there is none.
+func baseline(bb benchcmp.BenchSet, name string) *benchcmp.Bench {
+	if runs := bb[name]; len(runs) > 0 {
+		return runs[0]
+	}
+	for full, runs := range bb {
+		if base, _ := benchcmp.SplitName(full); base == name && len(runs) > 0 {
+			return runs[0]
+		}
+	}
+	return nil
+}
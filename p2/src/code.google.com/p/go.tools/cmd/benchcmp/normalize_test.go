@@ -0,0 +1,52 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestNormalize(t *testing.T) {
+	all := benchcmp.NsOp | benchcmp.MbS | benchcmp.AllocsOp
+	bb := benchcmp.BenchSet{
+		"BenchmarkBase-8": {{Name: "BenchmarkBase-8", N: 100, NsOp: 200, MbS: 10, AllocsOp: 2, Measured: all, Extra: map[string]float64{"items/op": 4}}},
+		"BenchmarkSlow-8": {{
+			Name: "BenchmarkSlow-8", N: 10, NsOp: 500, MbS: 5, AllocsOp: 3, Measured: all,
+			Extra:   map[string]float64{"items/op": 2, "other/op": 7},
+			Samples: map[string][]float64{"ns/op": {400, 600}, "allocs/op": {3, 3}},
+		}},
+	}
+	norm, err := normalize("new.txt", bb, "BenchmarkBase")
+	if err != nil {
+		t.Fatalf("normalize failed: %v", err)
+	}
+	base, slow := norm["BenchmarkBase-8"][0], norm["BenchmarkSlow-8"][0]
+	if base.NsOp != 1 || base.MbS != 1 || base.AllocsOp != 2 || base.Extra["items/op"] != 1 {
+		t.Errorf("normalize: want the base to be 1 have %+v", base)
+	}
+	if slow.NsOp != 2.5 || slow.MbS != 0.5 || slow.AllocsOp != 3 || slow.N != 10 {
+		t.Errorf("normalize: want 2.5 ns/op, 0.5 MB/s, 3 allocs have %+v", slow)
+	}
+	if want := map[string]float64{"items/op": 0.5, "other/op": 7}; !reflect.DeepEqual(want, slow.Extra) {
+		t.Errorf("normalize extra: want %v have %v", want, slow.Extra)
+	}
+	if want := map[string][]float64{"ns/op": {2, 3}, "allocs/op": {3, 3}}; !reflect.DeepEqual(want, slow.Samples) {
+		t.Errorf("normalize samples: want %v have %v", want, slow.Samples)
+	}
+	if bb["BenchmarkSlow-8"][0].NsOp != 500 || bb["BenchmarkSlow-8"][0].Samples["ns/op"][0] != 400 {
+		t.Errorf("normalize modified its input")
+	}
+
+	if _, err := normalize("new.txt", bb, "BenchmarkMissing"); err == nil || err.Error() != "-normalize: no benchmark BenchmarkMissing in new.txt" {
+		t.Errorf("normalize of a missing base: have %v", err)
+	}
+	bb["BenchmarkBase-8"][0].Measured = benchcmp.AllocsOp
+	if _, err := normalize("new.txt", bb, "BenchmarkBase-8"); err == nil {
+		t.Errorf("normalize by a base without ns/op should have failed")
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// prefixRE matches the log prefix that -prefix-strip removes from the
+// start of each line of input, or is nil.
+var prefixRE *regexp.Regexp
+
+// compilePrefix compiles expr, the argument of -prefix-strip, into a
+// regular expression matching it only at the start of a line.
+func compilePrefix(expr string) (*regexp.Regexp, error) {
+	if _, err := regexp.Compile(expr); err != nil {
+		return nil, err // report the error in expr as given
+	}
+	return regexp.Compile(`(?m)^(?:` + expr + `)`)
+}
+
+// stripPrefixes returns a reader of the contents of r with the text
+// matched by re, as compiled by compilePrefix, removed from the start
+// of each line, so that benchmark results written by a CI system with
+// a timestamp or other prefix on each line can be parsed. The input is
+// read a line at a time, as benchcmp.ParseLog reads it, and a line
+// longer than benchcmp.DefaultMaxLineSize is an error.
+func stripPrefixes(r io.Reader, re *regexp.Regexp) io.Reader {
+	return &prefixReader{r: bufio.NewReader(r), re: re, max: benchcmp.DefaultMaxLineSize}
+}
+
+// A prefixReader is the reader returned by stripPrefixes.
+type prefixReader struct {
+	r    *bufio.Reader
+	re   *regexp.Regexp
+	max  int    // longest line accepted, without its line ending
+	buf  []byte // the current line, with its prefix removed
+	rest []byte // the part of buf not yet read
+	line int    // the number of the current line, starting at 1
+	err  error  // the error that ended the current line, if any
+}
+
+func (p *prefixReader) Read(b []byte) (int, error) {
+	for len(p.rest) == 0 {
+		if p.err != nil {
+			return 0, p.err
+		}
+		p.readLine()
+	}
+	n := copy(b, p.rest)
+	p.rest = p.rest[n:]
+	return n, nil
+}
+
+// readLine reads the next line, including its newline, into p.buf
+// and p.rest, with its prefix removed.
+func (p *prefixReader) readLine() {
+	p.buf = p.buf[:0]
+	for {
+		chunk, err := p.r.ReadSlice('\n')
+		if len(p.buf)+len(bytes.TrimRight(chunk, "\r\n")) > p.max {
+			p.rest, p.err = nil, fmt.Errorf("line %d: longer than %d bytes", p.line+1, p.max)
+			return
+		}
+		p.buf = append(p.buf, chunk...)
+		if err != bufio.ErrBufferFull {
+			p.line++
+			p.rest, p.err = p.buf, err
+			if loc := p.re.FindIndex(p.buf); loc != nil && loc[0] == 0 {
+				p.rest = p.buf[loc[1]:]
+			}
+			return
+		}
+	}
+}
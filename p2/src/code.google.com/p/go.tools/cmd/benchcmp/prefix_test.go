@@ -0,0 +1,62 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestStripPrefixes(t *testing.T) {
+	in := `2014-01-02T15:04:05Z go build ./...
+2014-01-02T15:04:06Z BenchmarkEncrypt	5000000	300 ns/op
+2014-01-02T15:04:07Z BenchmarkDecrypt	2000000	500 ns/op
+ok at 2014-01-02T15:04:08Z PASS
+`
+	re, err := compilePrefix(`\d{4}-\d\d-\d\dT[\d:]+Z `)
+	if err != nil {
+		t.Fatalf("compilePrefix failed: %v", err)
+	}
+	data, err := ioutil.ReadAll(stripPrefixes(strings.NewReader(in), re))
+	if err != nil {
+		t.Fatalf("stripPrefixes failed: %v", err)
+	}
+	want := `go build ./...
+BenchmarkEncrypt	5000000	300 ns/op
+BenchmarkDecrypt	2000000	500 ns/op
+ok at 2014-01-02T15:04:08Z PASS
+`
+	if have := string(data); have != want {
+		t.Errorf("stripPrefixes: want\n%s\nhave\n%s", want, have)
+	}
+
+	log, err := benchcmp.ParseLog(stripPrefixes(strings.NewReader(in), re))
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	if len(log.Benchmarks) != 2 {
+		t.Errorf("ParseLog of stripped input: want 2 benchmarks have %v", log.Benchmarks)
+	}
+
+	// A last line without a newline is stripped too, and lines longer
+	// than ParseLog accepts are an error rather than read whole.
+	data, err = ioutil.ReadAll(stripPrefixes(strings.NewReader("2014-01-02T15:04:05Z PASS"), re))
+	if err != nil || string(data) != "PASS" {
+		t.Errorf("stripPrefixes of an unterminated line: have %q, %v", data, err)
+	}
+	long := strings.Repeat("x", benchcmp.DefaultMaxLineSize+1)
+	if _, err := ioutil.ReadAll(stripPrefixes(strings.NewReader("ok\n"+long+"\n"), re)); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("stripPrefixes of a long line: want error for line 2 have %v", err)
+	}
+
+	// An alternation applies as a whole at the start of each line.
+	re, _ = compilePrefix(`a|b`)
+	if have := re.ReplaceAllString("ab\nba\ncab\n", ""); have != "b\na\ncab\n" {
+		t.Errorf("compilePrefix(a|b): have %q", have)
+	}
+}
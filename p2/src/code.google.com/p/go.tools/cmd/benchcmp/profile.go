@@ -0,0 +1,29 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// profileURL returns the URL of the CPU profile of the benchmark named
+// name under base, as set by -profile-base: base/name.prof, with the
+// name escaped as a path.
+func profileURL(base, name string) string {
+	return strings.TrimSuffix(base, "/") + "/" + (&url.URL{Path: name + ".prof"}).String()
+}
+
+// profileLink returns the URL of the profile of the new run of cmp, if
+// -profile-base is set and cmp regressed in the measurement described
+// by s, and "" otherwise.
+func (s section) profileLink(cmp benchcmp.BenchCmp) string {
+	if *profileBase == "" || s.classify(cmp, threshold) <= 0 {
+		return ""
+	}
+	return profileURL(*profileBase, cmp.After.Name)
+}
@@ -0,0 +1,61 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestProfileURL(t *testing.T) {
+	for _, tt := range []struct{ base, name, want string }{
+		{"https://ci.example.com/prof", "BenchmarkEncrypt-8", "https://ci.example.com/prof/BenchmarkEncrypt-8.prof"},
+		{"https://ci.example.com/prof/", "BenchmarkEncrypt", "https://ci.example.com/prof/BenchmarkEncrypt.prof"},
+		{"/prof", "BenchmarkJSON/size=1k", "/prof/BenchmarkJSON/size=1k.prof"},
+		{"/prof", "BenchmarkA b?#", "/prof/BenchmarkA%20b%3F%23.prof"},
+	} {
+		if have := profileURL(tt.base, tt.name); have != tt.want {
+			t.Errorf("profileURL(%q, %q): want %q have %q", tt.base, tt.name, tt.want, have)
+		}
+	}
+}
+
+func TestProfileLinks(t *testing.T) {
+	defer func(saved string) { *profileBase = saved }(*profileBase)
+	defer func(saved thresholds) { threshold = saved }(threshold)
+	threshold = thresholds{"": 5}
+
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkSlower", NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkSlower", NsOp: 120, Measured: benchcmp.NsOp},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkFaster", NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkFaster", NsOp: 80, Measured: benchcmp.NsOp},
+		},
+	}
+	*profileBase = "https://ci.example.com/prof"
+	buf := new(bytes.Buffer)
+	renderMarkdown(buf, cmps)
+	if out := buf.String(); !strings.Contains(out, "| [BenchmarkSlower](https://ci.example.com/prof/BenchmarkSlower.prof) |") || strings.Contains(out, "BenchmarkFaster.prof") {
+		t.Errorf("renderMarkdown: want a link for the regression only:\n%s", out)
+	}
+	buf.Reset()
+	renderHTML(buf, cmps)
+	if out := buf.String(); !strings.Contains(out, `<a href="https://ci.example.com/prof/BenchmarkSlower.prof">BenchmarkSlower</a>`) || strings.Contains(out, "BenchmarkFaster.prof") {
+		t.Errorf("renderHTML: want a link for the regression only:\n%s", out)
+	}
+
+	*profileBase = ""
+	buf.Reset()
+	renderMarkdown(buf, cmps)
+	if strings.Contains(buf.String(), ".prof") {
+		t.Errorf("renderMarkdown without -profile-base: want no links:\n%s", buf)
+	}
+}
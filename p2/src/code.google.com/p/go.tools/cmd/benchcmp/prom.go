@@ -0,0 +1,93 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// promEscaper escapes a Prometheus label value.
+var promEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// promName returns the Prometheus metric name for the measurement with
+// the given unit: benchmark_ followed by the snakeName of the unit, or
+// just benchmark if that is empty.
+func promName(unit string) string {
+	if name := snakeName(unit); name != "" {
+		return "benchmark_" + name
+	}
+	return "benchmark"
+}
+
+// snakeName returns s in lower case, with each run of characters other
+// than letters, digits, _ and : replaced by a single _, and without
+// leading or trailing underscores.
+func snakeName(s string) string {
+	var name []byte
+	under := true
+	for _, c := range []byte(strings.ToLower(s)) {
+		if 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '_' || c == ':' {
+			name = append(name, c)
+			under = c == '_'
+		} else if !under {
+			name = append(name, '_')
+			under = true
+		}
+	}
+	return strings.Trim(string(name), "_")
+}
+
+// renderProm writes cmps to w in the Prometheus text exposition format,
+// for the textfile collector of the node exporter: a gauge for each
+// measurement of the new run, labeled by benchmark name, and the gauge
+// benchmark_delta_percent holding the percent change in each,
+// labeled by benchmark name and metric.
+func renderProm(w io.Writer, cmps []benchcmp.BenchCmp) error {
+	bw := bufio.NewWriter(w)
+	all := selectSections(allSections(cmps))
+	for _, s := range all {
+		name := promName(s.unit)
+		header := false
+		for _, cmp := range cmps {
+			if !s.measured(cmp) {
+				continue
+			}
+			if !header {
+				fmt.Fprintf(bw, "# HELP %s Benchmark measurement in %s.\n", name, s.unit)
+				fmt.Fprintf(bw, "# TYPE %s gauge\n", name)
+				header = true
+			}
+			fmt.Fprintf(bw, "%s{name=\"%s\"} %s\n", name, promEscaper.Replace(cmp.Name()), formatFloat(s.delta(cmp).After))
+		}
+	}
+
+	header := false
+	for _, s := range all {
+		for _, cmp := range cmps {
+			if !s.measured(cmp) {
+				continue
+			}
+			// A change from zero has no percentage.
+			f := s.delta(cmp).Float64()
+			if math.IsInf(f, 0) {
+				continue
+			}
+			if !header {
+				fmt.Fprintln(bw, "# HELP benchmark_delta_percent Percent change in a benchmark measurement.")
+				fmt.Fprintln(bw, "# TYPE benchmark_delta_percent gauge")
+				header = true
+			}
+			fmt.Fprintf(bw, "benchmark_delta_percent{name=\"%s\",metric=\"%s\"} %s\n",
+				promEscaper.Replace(cmp.Name()), promEscaper.Replace(s.unit), formatFloat(100*f-100))
+		}
+	}
+	return bw.Flush()
+}
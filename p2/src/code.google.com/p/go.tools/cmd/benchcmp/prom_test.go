@@ -0,0 +1,59 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestPromName(t *testing.T) {
+	cases := []struct {
+		unit, want string
+	}{
+		{"ns/op", "benchmark_ns_op"},
+		{"MB/s", "benchmark_mb_s"},
+		{"B/op", "benchmark_b_op"},
+		{"µs/req", "benchmark_s_req"},
+		{"items -/op", "benchmark_items_op"},
+		{"hits:total", "benchmark_hits:total"},
+		{"_hits_", "benchmark_hits"},
+		{"/op", "benchmark_op"},
+		{"%", "benchmark"},
+	}
+	for _, tt := range cases {
+		if have := promName(tt.unit); tt.want != have {
+			t.Errorf("promName(%q): want %q have %q", tt.unit, tt.want, have)
+		}
+	}
+}
+
+func TestRenderProm(t *testing.T) {
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: `BenchmarkQuote/"a\b"`, NsOp: 100, AllocsOp: 0, Measured: benchcmp.NsOp | benchcmp.AllocsOp},
+			After:  &benchcmp.Bench{Name: `BenchmarkQuote/"a\b"`, NsOp: 80, AllocsOp: 2, Measured: benchcmp.NsOp | benchcmp.AllocsOp},
+		},
+	}
+	buf := new(bytes.Buffer)
+	if err := renderProm(buf, cmps); err != nil {
+		t.Fatalf("renderProm failed: %v", err)
+	}
+	want := `# HELP benchmark_ns_op Benchmark measurement in ns/op.
+# TYPE benchmark_ns_op gauge
+benchmark_ns_op{name="BenchmarkQuote/\"a\\b\""} 80
+# HELP benchmark_allocs_op Benchmark measurement in allocs/op.
+# TYPE benchmark_allocs_op gauge
+benchmark_allocs_op{name="BenchmarkQuote/\"a\\b\""} 2
+# HELP benchmark_delta_percent Percent change in a benchmark measurement.
+# TYPE benchmark_delta_percent gauge
+benchmark_delta_percent{name="BenchmarkQuote/\"a\\b\"",metric="ns/op"} -20
+`
+	if have := buf.String(); want != have {
+		t.Errorf("renderProm incorrect output:\nwant %s\nhave %s", want, have)
+	}
+}
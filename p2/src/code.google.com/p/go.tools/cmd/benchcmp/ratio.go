@@ -0,0 +1,34 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strconv"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// showRatio reports whether the ratio column of -ratio is displayed
+// for s: only for ns/op, allocs, and bytes.
+func (s section) showRatio() bool {
+	if !*ratio {
+		return false
+	}
+	switch s.unit {
+	case "ns/op", "allocs/op", "B/op":
+		return true
+	}
+	return false
+}
+
+// formatRatio formats d as the ratio of its new value to its old, as
+// in 0.80 for 20% less. Like Delta.Percent, a change from zero is
+// formatted as "?", and no change from zero as 1.00.
+func formatRatio(d benchcmp.Delta) string {
+	if d.Before == 0 && d.After != 0 {
+		return "?"
+	}
+	return strconv.FormatFloat(d.Float64(), 'f', 2, 64)
+}
@@ -0,0 +1,57 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestFormatRatio(t *testing.T) {
+	for _, tt := range []struct {
+		before, after float64
+		want          string
+	}{
+		{100, 80, "0.80"},
+		{100, 125, "1.25"},
+		{3, 3, "1.00"},
+		{0, 0, "1.00"},
+		{0, 5, "?"},
+		{5, 0, "0.00"},
+	} {
+		if have := formatRatio(benchcmp.Delta{Before: tt.before, After: tt.after}); have != tt.want {
+			t.Errorf("formatRatio(%v, %v): want %q have %q", tt.before, tt.after, tt.want, have)
+		}
+	}
+}
+
+func TestRatioColumn(t *testing.T) {
+	defer func(saved bool) { *ratio = saved }(*ratio)
+	*ratio = true
+
+	m := benchcmp.NsOp | benchcmp.MbS | benchcmp.AllocsOp
+	cmp := benchcmp.BenchCmp{
+		Before: &benchcmp.Bench{Name: "BenchmarkA", NsOp: 100, MbS: 10, AllocsOp: 0, Measured: m},
+		After:  &benchcmp.Bench{Name: "BenchmarkA", NsOp: 80, MbS: 12.5, AllocsOp: 2, Measured: m},
+	}
+	for _, tt := range []struct {
+		s      section
+		header []string
+		cells  []string
+	}{
+		{sections[0], []string{"benchmark", "old ns/op", "new ns/op", "delta", "ratio"}, []string{"BenchmarkA", "100", "80.0", "-20.00%", "0.80"}},
+		{sections[1], []string{"benchmark", "old MB/s", "new MB/s", "speedup"}, []string{"BenchmarkA", "10.00", "12.50", "1.25x"}},
+		{sections[2], []string{"benchmark", "old allocs", "new allocs", "delta", "ratio"}, []string{"BenchmarkA", "0", "2", "?", "?"}},
+	} {
+		if have := tt.s.header(false); !reflect.DeepEqual(tt.header, have) {
+			t.Errorf("%s header with -ratio: want %q have %q", tt.s.unit, tt.header, have)
+		}
+		if have := tt.s.cells(cmp, false); !reflect.DeepEqual(tt.cells, have) {
+			t.Errorf("%s cells with -ratio: want %q have %q", tt.s.unit, tt.cells, have)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// worsening returns the percent change in d, signed so that positive
+// values are regressions of the measurement described by s.
+// A change from zero is infinite.
+func (s section) worsening(d benchcmp.Delta) float64 {
+	pct := 100*d.Float64() - 100
+	if d.Before == 0 && d.After == 0 {
+		pct = 0
+	}
+	if s.higher {
+		return -pct
+	}
+	return pct
+}
+
+// classify reports how the measurement of cmp described by s changed:
+// 1 if it got worse by more than its threshold, -1 if it got better by
+// more than its threshold, and 0 otherwise. Changes that are not
+// statistically significant are 0.
+func (s section) classify(cmp benchcmp.BenchCmp, threshold thresholds) int {
+	if p, ok := pValue(cmp, s.unit); ok && p > alpha {
+		return 0
+	}
+	w, t := s.worsening(s.delta(cmp)), threshold.of(s)
+	switch {
+	case w > t:
+		return 1
+	case w < -t:
+		return -1
+	}
+	return 0
+}
+
+// A regression is a measurement that got worse by more than
+// its threshold or, with -fail-on=any, better by more.
+type regression struct {
+	name      string
+	metric    string
+	change    string  // the change as displayed
+	threshold float64 // the threshold of the metric, in percent
+	improved  bool    // whether the measurement got better
+}
+
+func (r regression) String() string {
+	verb := "regressed"
+	if r.improved {
+		verb = "improved"
+	}
+	return fmt.Sprintf("%s %s %s: %s", r.name, r.metric, verb, r.change)
+}
+
+// findRegressions returns the measurements in cmps that got worse by
+// more than the threshold percent of their metric. Changes that are
+// not statistically significant are ignored.
+func findRegressions(cmps []benchcmp.BenchCmp, threshold thresholds) []regression {
+	return findChanges(cmps, threshold, false)
+}
+
+// findChanges is like findRegressions, but if improved is set it also
+// returns the measurements that got better by more than their threshold.
+func findChanges(cmps []benchcmp.BenchCmp, threshold thresholds, improved bool) []regression {
+	var regs []regression
+	for _, s := range allSections(cmps) {
+		for _, cmp := range cmps {
+			if !s.measured(cmp) {
+				continue
+			}
+			c := s.classify(cmp, threshold)
+			if c > 0 || c < 0 && improved {
+				regs = append(regs, regression{cmp.Name(), s.unit, s.show(s.delta(cmp)), threshold.of(s), c < 0})
+			}
+		}
+	}
+	return regs
+}
+
+// worstRegression returns the benchmark of cmps whose ns/op regressed
+// the most beyond -threshold, as shown by -worst, and reports whether
+// any did. Of equal regressions, that of the first name is returned.
+func worstRegression(cmps []benchcmp.BenchCmp) (regression, bool) {
+	s := sections[0]
+	var worst benchcmp.BenchCmp
+	var max float64
+	found := false
+	for _, cmp := range cmps {
+		if !s.measured(cmp) || s.classify(cmp, threshold) <= 0 {
+			continue
+		}
+		w := s.worsening(s.delta(cmp))
+		if !found || w > max || w == max && cmp.Name() < worst.Name() {
+			worst, max, found = cmp, w, true
+		}
+	}
+	if !found {
+		return regression{}, false
+	}
+	return regression{worst.Name(), s.unit, s.show(s.delta(worst)), threshold.of(s), false}, true
+}
+
+// failModes holds the changes that fail -ci, keyed by -fail-on mode.
+var failModes = map[string]func([]benchcmp.BenchCmp, thresholds) []regression{
+	"regression": findRegressions,
+	"any": func(cmps []benchcmp.BenchCmp, threshold thresholds) []regression {
+		return findChanges(cmps, threshold, true)
+	},
+	"none": func([]benchcmp.BenchCmp, thresholds) []regression { return nil },
+}
+
+// checkRegressions reports any changes in cmps beyond -threshold that
+// fail under -fail-on to standard error and exits with status 1 if
+// there are any.
+func checkRegressions(cmps []benchcmp.BenchCmp) {
+	regs := failModes[*failOn](cmps, threshold)
+	if len(regs) == 0 {
+		return
+	}
+	for _, r := range regs {
+		fmt.Fprintf(os.Stderr, "benchcmp: %s (threshold %.2f%%)\n", r, r.threshold)
+	}
+	os.Exit(1)
+}
+
+// renderCounts writes to w a line for each measurement in sections
+// counting the benchmarks in cmps that improved, regressed, and stayed
+// within the threshold, whether or not -changed shows them. Measurements
+// that no benchmark reported are left out.
+func renderCounts(w io.Writer, sections []section, cmps []benchcmp.BenchCmp) {
+	for _, s := range sections {
+		var n [3]int // improved, unchanged, regressed
+		for _, cmp := range cmps {
+			if s.measured(cmp) {
+				n[s.classify(cmp, threshold)+1]++
+			}
+		}
+		if n[0]+n[1]+n[2] == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s: %d improved, %d regressed, %d unchanged\n", s.unit, n[0], n[2], n[1])
+	}
+}
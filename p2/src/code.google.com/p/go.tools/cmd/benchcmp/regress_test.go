@@ -0,0 +1,118 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestFindRegressions(t *testing.T) {
+	all := benchcmp.NsOp | benchcmp.MbS | benchcmp.AllocsOp
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkSlower", NsOp: 100, MbS: 10, Measured: all},
+			After:  &benchcmp.Bench{Name: "BenchmarkSlower", NsOp: 110, MbS: 9, Measured: all},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkFaster", NsOp: 100, MbS: 10, AllocsOp: 1, Measured: all},
+			After:  &benchcmp.Bench{Name: "BenchmarkFaster", NsOp: 50, MbS: 20, AllocsOp: 0, Measured: all},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkNoise", NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkNoise", NsOp: 104, Measured: benchcmp.NsOp},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkAllocs", AllocsOp: 0, Extra: map[string]float64{"req/s": 10}, Measured: benchcmp.AllocsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkAllocs", AllocsOp: 2, Extra: map[string]float64{"req/s": 8}, Measured: benchcmp.AllocsOp},
+		},
+	}
+
+	want := []regression{
+		{"BenchmarkSlower", "ns/op", "+10.00%", 5, false},
+		{"BenchmarkSlower", "MB/s", "0.90x", 5, false},
+		{"BenchmarkAllocs", "allocs/op", "?", 5, false},
+		{"BenchmarkAllocs", "req/s", "-20.00%", 5, false},
+	}
+	if have := findRegressions(cmps, thresholds{"": 5}); !reflect.DeepEqual(want, have) {
+		t.Errorf("findRegressions: want %v have %v", want, have)
+	}
+
+	both := []regression{
+		{"BenchmarkSlower", "ns/op", "+10.00%", 5, false},
+		{"BenchmarkFaster", "ns/op", "-50.00%", 5, true},
+		{"BenchmarkSlower", "MB/s", "0.90x", 5, false},
+		{"BenchmarkFaster", "MB/s", "2.00x", 5, true},
+		{"BenchmarkFaster", "allocs/op", "-100.00%", 5, true},
+		{"BenchmarkAllocs", "allocs/op", "?", 5, false},
+		{"BenchmarkAllocs", "req/s", "-20.00%", 5, false},
+	}
+	for mode, want := range map[string][]regression{"regression": want, "any": both, "none": nil} {
+		if have := failModes[mode](cmps, thresholds{"": 5}); !reflect.DeepEqual(want, have) {
+			t.Errorf("-fail-on=%s: want %v have %v", mode, want, have)
+		}
+	}
+	if s := both[1].String(); s != "BenchmarkFaster ns/op improved: -50.00%" {
+		t.Errorf("regression.String: have %q", s)
+	}
+}
+
+func TestWorstRegression(t *testing.T) {
+	defer func(saved thresholds) { threshold = saved }(threshold)
+	threshold = thresholds{"": 5}
+
+	ns := func(name string, before, after float64) benchcmp.BenchCmp {
+		return benchcmp.BenchCmp{
+			Before: &benchcmp.Bench{Name: name, NsOp: before, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: name, NsOp: after, Measured: benchcmp.NsOp},
+		}
+	}
+	cmps := []benchcmp.BenchCmp{
+		ns("BenchmarkSlower", 100, 110),
+		ns("BenchmarkSlowest", 100, 150),
+		ns("BenchmarkFaster", 100, 10),
+		ns("BenchmarkNoise", 100, 104),
+	}
+	r, ok := worstRegression(cmps)
+	if !ok || r.String() != "BenchmarkSlowest ns/op regressed: +50.00%" {
+		t.Errorf("worstRegression: have %v, %v", r, ok)
+	}
+	if r, ok := worstRegression(cmps[2:]); ok {
+		t.Errorf("worstRegression without regressions: have %v", r)
+	}
+}
+
+func TestRenderCounts(t *testing.T) {
+	defer func(saved thresholds) { threshold = saved }(threshold)
+	threshold = thresholds{"": 5, "allocs": 0}
+
+	all := benchcmp.NsOp | benchcmp.AllocsOp
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkSlower", NsOp: 100, AllocsOp: 2, Measured: all},
+			After:  &benchcmp.Bench{Name: "BenchmarkSlower", NsOp: 110, AllocsOp: 3, Measured: all},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkFaster", NsOp: 100, AllocsOp: 2, Measured: all},
+			After:  &benchcmp.Bench{Name: "BenchmarkFaster", NsOp: 50, AllocsOp: 2, Measured: all},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkNoise", NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkNoise", NsOp: 96, Measured: benchcmp.NsOp},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	renderCounts(buf, allSections(cmps), cmps)
+	want := `ns/op: 1 improved, 1 regressed, 1 unchanged
+allocs/op: 0 improved, 1 regressed, 1 unchanged
+`
+	if have := buf.String(); want != have {
+		t.Errorf("renderCounts: want\n%s\nhave\n%s", want, have)
+	}
+}
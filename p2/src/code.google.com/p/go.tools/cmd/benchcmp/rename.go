@@ -0,0 +1,54 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// readRenames reads the benchmark renames in the named file.
+// See parseRenames.
+func readRenames(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseRenames(path, f)
+}
+
+// parseRenames parses a list of benchmark renames, one per line
+// in the form old=new, from r, which was read from path.
+// Blank lines and lines beginning with # are ignored.
+func parseRenames(path string, r io.Reader) (map[string]string, error) {
+	names := make(map[string]string)
+	scan := bufio.NewScanner(r)
+	for line := 1; scan.Scan(); line++ {
+		text := strings.TrimSpace(scan.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		i := strings.Index(text, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("%s:%d: want old=new, have %q", path, line, text)
+		}
+		from, to := strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:])
+		if from == "" || to == "" || strings.ContainsAny(from+to, " \t=") {
+			return nil, fmt.Errorf("%s:%d: want old=new, have %q", path, line, text)
+		}
+		if _, dup := names[from]; dup {
+			return nil, fmt.Errorf("%s:%d: %s renamed more than once", path, line, from)
+		}
+		names[from] = to
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRenames(t *testing.T) {
+	in := `
+# Renamed in the cipher refactor.
+BenchmarkOldName=BenchmarkNewName
+	BenchmarkEncrypt = BenchmarkSeal
+`
+	names, err := parseRenames("renames.txt", strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("parseRenames failed: %v", err)
+	}
+	want := map[string]string{
+		"BenchmarkOldName": "BenchmarkNewName",
+		"BenchmarkEncrypt": "BenchmarkSeal",
+	}
+	if !reflect.DeepEqual(want, names) {
+		t.Errorf("parseRenames: want %v have %v", want, names)
+	}
+
+	bad := []struct {
+		in, err string
+	}{
+		{in: "BenchmarkNoEquals", err: `renames.txt:1: want old=new, have "BenchmarkNoEquals"`},
+		{in: "\n=BenchmarkNew", err: `renames.txt:2: want old=new, have "=BenchmarkNew"`},
+		{in: "BenchmarkOld=", err: `renames.txt:1: want old=new, have "BenchmarkOld="`},
+		{in: "BenchmarkA=B=C", err: `renames.txt:1: want old=new, have "BenchmarkA=B=C"`},
+		{in: "BenchmarkA B=BenchmarkC", err: `renames.txt:1: want old=new, have "BenchmarkA B=BenchmarkC"`},
+		{in: "BenchmarkA=BenchmarkB\nBenchmarkA=BenchmarkC", err: "renames.txt:2: BenchmarkA renamed more than once"},
+	}
+	for _, tt := range bad {
+		_, err := parseRenames("renames.txt", strings.NewReader(tt.in))
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("parseRenames(%q): want error %q have %v", tt.in, tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// sampleCount returns the number of runs merged into b, as by go test
+// -count: the most samples of any of its measurements, or 1 if it ran
+// only once.
+func sampleCount(b *benchcmp.Bench) int {
+	n := 1
+	for _, x := range b.Samples {
+		if len(x) > n {
+			n = len(x)
+		}
+	}
+	return n
+}
+
+// fewSamples returns a message for each benchmark of cmps with fewer
+// than min runs in either file, naming how many it has in each.
+// Counts may differ from one benchmark to the next, so each is
+// checked on its own.
+func fewSamples(cmps []benchcmp.BenchCmp, min int) []string {
+	var msgs []string
+	for _, cmp := range cmps {
+		nOld, nNew := sampleCount(cmp.Before), sampleCount(cmp.After)
+		if nOld < min || nNew < min {
+			msgs = append(msgs, fmt.Sprintf("%s has %d old and %d new runs, fewer than -min-samples=%d", cmp.Name(), nOld, nNew, min))
+		}
+	}
+	return msgs
+}
+
+// fewSamplesN returns a message for each benchmark of cmps with fewer
+// than min runs in any of the files compared, naming how many it has in
+// each, in the order of the files.
+func fewSamplesN(cmps []benchcmp.BenchCmpN, min int) []string {
+	var msgs []string
+	for _, cmp := range cmps {
+		few := false
+		counts := make([]string, len(cmp.Benches))
+		for i, b := range cmp.Benches {
+			n := sampleCount(b)
+			few = few || n < min
+			counts[i] = strconv.Itoa(n)
+		}
+		if few {
+			msgs = append(msgs, fmt.Sprintf("%s has %s runs, fewer than -min-samples=%d", cmp.Name(), strings.Join(counts, ", "), min))
+		}
+	}
+	return msgs
+}
+
+// warnFewSamples warns on standard error about the benchmarks of msgs,
+// as returned by fewSamples or fewSamplesN, whose changes are too weakly
+// supported to trust, listed as by warnList.
+func warnFewSamples(msgs []string) {
+	if len(msgs) == 0 {
+		return
+	}
+	fmt.Fprintf(stderr, "benchcmp: WARNING: %d benchmarks have too few runs:\n", len(msgs))
+	warnList(msgs)
+}
+
+// checkSamples reports the benchmarks of msgs, as returned by fewSamples
+// or fewSamplesN, to standard error and exits with status 1 if there are
+// any, failing -ci.
+func checkSamples(msgs []string) {
+	if len(msgs) == 0 {
+		return
+	}
+	for _, msg := range msgs {
+		fmt.Fprintf(os.Stderr, "benchcmp: %s\n", msg)
+	}
+	os.Exit(1)
+}
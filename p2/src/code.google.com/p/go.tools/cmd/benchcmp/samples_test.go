@@ -0,0 +1,141 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestFewSamples(t *testing.T) {
+	runs := func(name string, n int) *benchcmp.Bench {
+		b := &benchcmp.Bench{Name: name, NsOp: 100, Measured: benchcmp.NsOp}
+		if n > 1 {
+			b.Samples = map[string][]float64{"ns/op": make([]float64, n)}
+		}
+		return b
+	}
+	cmps := []benchcmp.BenchCmp{
+		{Before: runs("BenchmarkEnough", 10), After: runs("BenchmarkEnough", 10)},
+		{Before: runs("BenchmarkOnce", 1), After: runs("BenchmarkOnce", 10)},
+		{Before: runs("BenchmarkFewNew", 5), After: runs("BenchmarkFewNew", 3)},
+	}
+	want := []string{
+		"BenchmarkOnce has 1 old and 10 new runs, fewer than -min-samples=5",
+		"BenchmarkFewNew has 5 old and 3 new runs, fewer than -min-samples=5",
+	}
+	if have := fewSamples(cmps, 5); !reflect.DeepEqual(want, have) {
+		t.Errorf("fewSamples: want %q have %q", want, have)
+	}
+	if have := fewSamples(cmps, 0); have != nil {
+		t.Errorf("fewSamples with no minimum: want nil have %q", have)
+	}
+}
+
+func TestFewSamplesN(t *testing.T) {
+	runs := func(n int) *benchcmp.Bench {
+		b := &benchcmp.Bench{Name: "BenchmarkA", NsOp: 100, Measured: benchcmp.NsOp}
+		if n > 1 {
+			b.Samples = map[string][]float64{"ns/op": make([]float64, n)}
+		}
+		return b
+	}
+	cmps := []benchcmp.BenchCmpN{
+		{Benches: []*benchcmp.Bench{runs(5), runs(5), runs(5)}},
+		{Benches: []*benchcmp.Bench{runs(5), runs(2), runs(5)}},
+	}
+	want := []string{"BenchmarkA has 5, 2, 5 runs, fewer than -min-samples=5"}
+	if have := fewSamplesN(cmps, 5); !reflect.DeepEqual(want, have) {
+		t.Errorf("fewSamplesN: want %q have %q", want, have)
+	}
+}
+
+// discardOutput sends stdout, which closeOutput closes, to the null
+// device, returning a function that restores it.
+func discardOutput(t *testing.T) func() {
+	saved := stdout
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout = f
+	return func() {
+		f.Close()
+		stdout = saved
+	}
+}
+
+// TestMinSamplesDirsAndN checks that directory and N-way comparisons
+// warn about too few runs, or under -ci leave them to checkSamples.
+func TestMinSamplesDirsAndN(t *testing.T) {
+	defer func(saved io.Writer) { stderr = saved }(stderr)
+	defer func(saved int) { *minSamples = saved }(*minSamples)
+	defer func(saved bool) { *ciMode = saved }(*ciMode)
+	*minSamples = 3
+
+	dir, err := ioutil.TempDir("", "benchcmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	once := "BenchmarkA\t100\t10 ns/op\n"
+	thrice := strings.Repeat(once, 3)
+	oldDir, newDir := filepath.Join(dir, "old"), filepath.Join(dir, "new")
+	for path, log := range map[string]string{
+		filepath.Join(oldDir, "pkg.txt"): once,
+		filepath.Join(newDir, "pkg.txt"): thrice,
+		filepath.Join(dir, "1.txt"):      once,
+		filepath.Join(dir, "2.txt"):      thrice,
+		filepath.Join(dir, "3.txt"):      once,
+	} {
+		os.MkdirAll(filepath.Dir(path), 0777)
+		if err := ioutil.WriteFile(path, []byte(log), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths := []string{filepath.Join(dir, "1.txt"), filepath.Join(dir, "2.txt"), filepath.Join(dir, "3.txt")}
+
+	for _, ci := range []bool{false, true} {
+		*ciMode = ci
+		buf := new(bytes.Buffer)
+		stderr = buf
+		restore := discardOutput(t)
+		all := compareDirs(oldDir, newDir)
+		restore()
+		want := "benchcmp: WARNING: 1 benchmarks have too few runs:\n\tBenchmarkA has 1 old and 3 new runs, fewer than -min-samples=3\n"
+		if ci {
+			want = ""
+		}
+		if have := buf.String(); have != want {
+			t.Errorf("compareDirs with -ci=%v: want warning %q have %q", ci, want, have)
+		}
+		if msgs := fewSamples(all, *minSamples); len(msgs) != 1 {
+			t.Errorf("compareDirs with -ci=%v: want 1 benchmark with too few runs have %q", ci, msgs)
+		}
+
+		buf.Reset()
+		restore = discardOutput(t)
+		cmps := compareN(paths)
+		restore()
+		want = "benchcmp: WARNING: 1 benchmarks have too few runs:\n\tBenchmarkA has 1, 3, 1 runs, fewer than -min-samples=3\n"
+		if ci {
+			want = ""
+		}
+		if have := buf.String(); have != want {
+			t.Errorf("compareN with -ci=%v: want warning %q have %q", ci, want, have)
+		}
+		if msgs := fewSamplesN(cmps, *minSamples); len(msgs) != 1 {
+			t.Errorf("compareN with -ci=%v: want 1 benchmark with too few runs have %q", ci, msgs)
+		}
+	}
+}
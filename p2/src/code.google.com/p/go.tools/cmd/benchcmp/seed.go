@@ -0,0 +1,40 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// shuffleKey returns the position of the benchmark named name in the
+// shuffle of all names given by seed. Each seed orders names in its own
+// arbitrary but repeatable way.
+func shuffleKey(seed int64, name string) uint64 {
+	h := fnv.New64a()
+	binary.Write(h, binary.LittleEndian, seed)
+	h.Write([]byte(name))
+	return h.Sum64()
+}
+
+// tieBreak returns the order of benchmarks whose changes tie in a sort,
+// as set by -seed-order: that of the shuffle of their names by the seed,
+// then by name. Without -seed-order, it returns nil, and ties are
+// ordered by name.
+func tieBreak() func(i, j benchcmp.BenchCmp) bool {
+	if *seedOrder == 0 {
+		return nil
+	}
+	seed := *seedOrder
+	return func(i, j benchcmp.BenchCmp) bool {
+		ki, kj := shuffleKey(seed, i.Name()), shuffleKey(seed, j.Name())
+		if ki != kj {
+			return ki < kj
+		}
+		return i.Name() < j.Name()
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestSeedOrder(t *testing.T) {
+	defer func(saved int64) { *seedOrder = saved }(*seedOrder)
+
+	// BenchmarkBig changes most; the others tie.
+	var cmps []benchcmp.BenchCmp
+	for i := 0; i < 8; i++ {
+		name, after := fmt.Sprintf("Benchmark%d", i), 110.0
+		if i == 5 {
+			name, after = "BenchmarkBig", 200
+		}
+		cmps = append(cmps, benchcmp.BenchCmp{
+			Before: &benchcmp.Bench{Name: name, NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: name, NsOp: after, Measured: benchcmp.NsOp},
+		})
+	}
+	order := func(seed int64) []string {
+		*seedOrder = seed
+		sort.Sort(sections[0].order(cmps))
+		var names []string
+		for _, cmp := range cmps {
+			names = append(names, cmp.Name())
+		}
+		return names
+	}
+
+	if want, have := []string{"BenchmarkBig", "Benchmark0", "Benchmark1", "Benchmark2", "Benchmark3", "Benchmark4", "Benchmark6", "Benchmark7"}, order(0); !reflect.DeepEqual(want, have) {
+		t.Errorf("unseeded order: want %v have %v", want, have)
+	}
+	first := order(1)
+	if first[0] != "BenchmarkBig" {
+		t.Errorf("-seed-order=1 reordered a change that does not tie: have %v", first)
+	}
+	if again := order(1); !reflect.DeepEqual(first, again) {
+		t.Errorf("-seed-order=1 is not repeatable: have %v then %v", first, again)
+	}
+	var differs bool
+	for seed := int64(2); seed < 10 && !differs; seed++ {
+		differs = !reflect.DeepEqual(first, order(seed))
+	}
+	if !differs {
+		t.Errorf("-seed-order gave the same order for seeds 1 to 9: %v", first)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// A timedFile is a file found by -since and its modification time.
+type timedFile struct {
+	path string
+	info os.FileInfo
+}
+
+// byModTime sorts timedFiles from the oldest to the newest. Files
+// modified at the same time are sorted by path, so that names holding
+// a date, as in bench-2014-01-02.txt, still sort in time order.
+type byModTime []timedFile
+
+func (x byModTime) Len() int      { return len(x) }
+func (x byModTime) Swap(i, j int) { x[i], x[j] = x[j], x[i] }
+func (x byModTime) Less(i, j int) bool {
+	ti, tj := x[i].info.ModTime(), x[j].info.ModTime()
+	if !ti.Equal(tj) {
+		return ti.Before(tj)
+	}
+	return x[i].path < x[j].path
+}
+
+// sinceFiles returns the files to compare with -since: of the files in
+// the directory dir, or matching the glob pattern dir otherwise, the
+// nth oldest, counting from 1, and the newest, by modification time.
+// As with directories compared, only regular files that are not hidden
+// are logs; subdirectories, hidden files such as editor swap files, and
+// broken symbolic links are ignored.
+func sinceFiles(dir string, nth int) (oldPath, newPath string, err error) {
+	var paths []string
+	if isDir(dir) {
+		infos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return "", "", err
+		}
+		for _, fi := range infos {
+			paths = append(paths, filepath.Join(dir, fi.Name()))
+		}
+	} else if paths, err = filepath.Glob(dir); err != nil {
+		return "", "", fmt.Errorf("invalid pattern %q: %v", dir, err)
+	}
+	var files []timedFile
+	for _, path := range paths {
+		if fi, err := os.Stat(path); err == nil && isLogFile(fi) {
+			files = append(files, timedFile{path, fi})
+		}
+	}
+	if len(files) < 2 {
+		return "", "", fmt.Errorf("-since needs at least two files in %s, found %d", dir, len(files))
+	}
+	if nth >= len(files) {
+		return "", "", fmt.Errorf("-range=%d leaves no newer file to compare with, of %d in %s", nth, len(files), dir)
+	}
+	sort.Sort(byModTime(files))
+	return files[nth-1].path, files[len(files)-1].path, nil
+}
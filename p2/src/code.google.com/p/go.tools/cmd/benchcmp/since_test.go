@@ -0,0 +1,91 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSinceFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchcmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Modified in an order that their names do not follow, except for
+	// the two of the same time.
+	base := time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, name := range []string{"c.txt", "a.txt", "b1.txt", "b2.txt", "d.log"} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+		mtime := base.Add(time.Duration(i) * time.Hour)
+		if name == "b2.txt" {
+			mtime = base.Add(2 * time.Hour)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Newer than the logs are a subdirectory, an editor's swap file,
+	// and a broken symbolic link, none of which are logs.
+	newest := base.Add(10 * time.Hour)
+	sub, swap := filepath.Join(dir, "sub"), filepath.Join(dir, ".d.log.swp")
+	if err := os.Mkdir(sub, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(swap, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{sub, swap} {
+		if err := os.Chtimes(path, newest, newest); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.Symlink(filepath.Join(dir, "missing.txt"), filepath.Join(dir, "e.txt"))
+
+	for _, tt := range []struct {
+		pattern  string
+		nth      int
+		old, new string
+	}{
+		{dir, 1, "c.txt", "d.log"},
+		{dir, 2, "a.txt", "d.log"},
+		{dir, 3, "b1.txt", "d.log"},
+		{dir, 4, "b2.txt", "d.log"},
+		{filepath.Join(dir, "*.txt"), 1, "c.txt", "b2.txt"},
+		{filepath.Join(dir, "*"), 1, "c.txt", "d.log"},
+	} {
+		oldPath, newPath, err := sinceFiles(tt.pattern, tt.nth)
+		if err != nil {
+			t.Errorf("sinceFiles(%s, %d) failed: %v", tt.pattern, tt.nth, err)
+			continue
+		}
+		if oldPath != filepath.Join(dir, tt.old) || newPath != filepath.Join(dir, tt.new) {
+			t.Errorf("sinceFiles(%s, %d): want %s, %s have %s, %s", tt.pattern, tt.nth, tt.old, tt.new, oldPath, newPath)
+		}
+	}
+
+	for _, tt := range []struct {
+		pattern string
+		nth     int
+	}{
+		{dir, 5},
+		{filepath.Join(dir, "*.log"), 1},
+		{filepath.Join(dir, "["), 1},
+		{filepath.Join(dir, ".*"), 1},
+	} {
+		if _, _, err := sinceFiles(tt.pattern, tt.nth); err == nil {
+			t.Errorf("sinceFiles(%s, %d): want error", tt.pattern, tt.nth)
+		}
+	}
+}
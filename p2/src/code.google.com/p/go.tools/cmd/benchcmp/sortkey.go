@@ -0,0 +1,50 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// keyNames returns the names by which -sort-key selects s: its label,
+// its unit, and its unit without "/op" or without slashes, as in ns
+// and nsop for ns/op.
+func keyNames(s section) []string {
+	return []string{s.label, s.unit, shortName(s), strings.Replace(s.unit, "/", "", -1)}
+}
+
+// validSortKey reports whether name may be given to -sort-key: a name of
+// a known measurement, or what may be the unit of a custom one.
+func validSortKey(name string) bool {
+	for _, s := range knownSections() {
+		for _, n := range keyNames(s) {
+			if name == n {
+				return true
+			}
+		}
+	}
+	return strings.Contains(name, "/")
+}
+
+// sortSection returns the section whose order, with -mag, sorts the
+// rows of every table for cmps: that named by -sort-key, so that each
+// benchmark keeps its row across the tables, or else ns/op. Benchmarks
+// that did not measure it sort as unchanged. A custom metric measured
+// by none of cmps is ignored in favor of ns/op.
+func sortSection(cmps []benchcmp.BenchCmp) section {
+	if *sortKey == "" {
+		return sections[0]
+	}
+	for _, s := range append(allSections(cmps), timeSection, allocRateSection, allocsRateSection) {
+		for _, n := range keyNames(s) {
+			if *sortKey == n {
+				return s
+			}
+		}
+	}
+	return sections[0]
+}
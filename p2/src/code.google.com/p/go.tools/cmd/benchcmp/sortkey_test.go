@@ -0,0 +1,77 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestValidSortKey(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want bool
+	}{
+		{"nsop", true},
+		{"ns", true},
+		{"ns/op", true},
+		{"allocs", true},
+		{"allocsop", true},
+		{"B/op", true},
+		{"items/op", true},
+		{"speed", false},
+	} {
+		if have := validSortKey(tt.name); have != tt.want {
+			t.Errorf("validSortKey(%q): want %t have %t", tt.name, tt.want, have)
+		}
+	}
+}
+
+func TestSortKey(t *testing.T) {
+	defer func(saved bool) { *magSort = saved }(*magSort)
+	defer func(saved string) { *sortKey = saved }(*sortKey)
+
+	cmp := func(name string, ns, allocs uint64) benchcmp.BenchCmp {
+		m := benchcmp.NsOp | benchcmp.AllocsOp
+		return benchcmp.BenchCmp{
+			Before: &benchcmp.Bench{Name: name, NsOp: 100, AllocsOp: 10, Measured: m},
+			After:  &benchcmp.Bench{Name: name, NsOp: float64(ns), AllocsOp: allocs, Measured: m},
+		}
+	}
+	// By ns/op, A changed most, then B, then C; by allocs, the reverse.
+	cmps := []benchcmp.BenchCmp{cmp("BenchmarkB", 150, 15), cmp("BenchmarkC", 110, 30), cmp("BenchmarkA", 300, 11)}
+
+	*magSort = true
+	for _, tt := range []struct {
+		key  string
+		want []string // row order of each table
+	}{
+		{"", []string{"A B C", "C B A"}},
+		{"nsop", []string{"A B C", "A B C"}},
+		{"allocs", []string{"C B A", "C B A"}},
+	} {
+		*sortKey = tt.key
+		buf := new(bytes.Buffer)
+		if err := renderText(buf, cmps); err != nil {
+			t.Fatalf("renderText failed: %v", err)
+		}
+		var have []string
+		for _, table := range strings.Split(buf.String(), "\n\n") {
+			var names []string
+			for _, line := range strings.Split(table, "\n")[1:] {
+				if strings.HasPrefix(line, "Benchmark") {
+					names = append(names, strings.TrimPrefix(strings.Fields(line)[0], "Benchmark"))
+				}
+			}
+			have = append(have, strings.Join(names, " "))
+		}
+		if strings.Join(have, "; ") != strings.Join(tt.want, "; ") {
+			t.Errorf("-sort-key=%q: want rows %q have %q", tt.key, tt.want, have)
+		}
+	}
+}
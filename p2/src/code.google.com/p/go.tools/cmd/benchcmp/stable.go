@@ -0,0 +1,58 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// The widths of the columns written by renderStable. A wider cell
+// widens only its own line.
+const (
+	stableNameWidth  = 40 // benchmark names, aligned left
+	stableValueWidth = 14 // values and changes, aligned right
+)
+
+// renderStable writes cmps to w as text tables like those of renderText,
+// but with columns of fixed width and rows sorted by name, so that when
+// the output is kept under version control, a change in one value
+// changes only its own line.
+func renderStable(w io.Writer, cmps []benchcmp.BenchCmp) error {
+	buf := new(bytes.Buffer)
+	var shown bool // Has any table been written yet?
+	for _, s := range tableSections(cmps) {
+		rows := s.rows(cmps)
+		if len(rows) == 0 {
+			continue
+		}
+		sort.Sort(benchcmp.ByName(rows))
+		if shown {
+			buf.WriteString("\n")
+		}
+		shown = true
+		sampled := hasPValues(cmps, s)
+		writeStableRow(buf, s.header(sampled))
+		for _, cmp := range rows {
+			writeStableRow(buf, s.cells(cmp, sampled))
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeStableRow writes one row of a table of renderStable to buf:
+// the name in the first of cells and the values in the rest.
+func writeStableRow(buf *bytes.Buffer, cells []string) {
+	fmt.Fprintf(buf, "%-*s", stableNameWidth, cells[0])
+	for _, c := range cells[1:] {
+		fmt.Fprintf(buf, " %*s", stableValueWidth, c)
+	}
+	buf.WriteString("\n")
+}
@@ -0,0 +1,54 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestRenderStable(t *testing.T) {
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkZip", NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkZip", NsOp: 120, Measured: benchcmp.NsOp},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkAdd", NsOp: 5, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkAdd", NsOp: 5, Measured: benchcmp.NsOp},
+		},
+	}
+	buf := new(bytes.Buffer)
+	if err := renderStable(buf, cmps); err != nil {
+		t.Fatalf("renderStable failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("renderStable: want 3 lines have\n%s", buf)
+	}
+	if !strings.HasPrefix(lines[1], "BenchmarkAdd ") || !strings.HasPrefix(lines[2], "BenchmarkZip ") {
+		t.Errorf("renderStable: rows not sorted by name:\n%s", buf)
+	}
+	for _, line := range lines {
+		if want := stableNameWidth + 3*(1+stableValueWidth); len(line) != want {
+			t.Errorf("renderStable: want line of width %d have %d: %q", want, len(line), line)
+		}
+	}
+	if want := strings.Repeat(" ", stableValueWidth-len("+20.00%")) + "+20.00%"; !strings.HasSuffix(lines[2], " "+want) {
+		t.Errorf("renderStable: want delta right-aligned in %d columns have %q", stableValueWidth, lines[2])
+	}
+
+	// Only the line of a changed value changes.
+	cmps[1].After.NsOp = 6
+	buf2 := new(bytes.Buffer)
+	renderStable(buf2, cmps)
+	lines2 := strings.Split(strings.TrimSuffix(buf2.String(), "\n"), "\n")
+	if lines2[0] != lines[0] || lines2[2] != lines[2] || lines2[1] == lines[1] {
+		t.Errorf("renderStable: a change in one value changed other lines:\n%s\n%s", buf, buf2)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// A summary describes the benchmarks of a single run, as shown
+// by -summary.
+type summary struct {
+	count  int      // number of benchmarks
+	timed  int      // number of benchmarks that measured ns/op
+	min    string   // benchmark with the least ns/op
+	max    string   // benchmark with the most ns/op
+	minNs  float64  // ns/op of min
+	maxNs  float64  // ns/op of max
+	meanNs float64  // mean ns/op of the timed benchmarks
+	memory []string // benchmarks that measured allocs or bytes, sorted
+}
+
+// summarize returns the summary of the benchmarks in bb selected by
+// -filter. Each benchmark is expected to have been run once, as after
+// benchcmp.MergeSamples.
+func summarize(bb benchcmp.BenchSet) summary {
+	var names []string
+	for name := range bb {
+		if selects(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var s summary
+	var ns []float64
+	for _, name := range names {
+		s.count++
+		b := bb[name][0]
+		if b.Measured&benchcmp.NsOp != 0 {
+			if len(ns) == 0 || b.NsOp < s.minNs {
+				s.min, s.minNs = name, b.NsOp
+			}
+			if len(ns) == 0 || b.NsOp > s.maxNs {
+				s.max, s.maxNs = name, b.NsOp
+			}
+			ns = append(ns, b.NsOp)
+		}
+		if b.Measured&(benchcmp.AllocsOp|benchcmp.BOp) != 0 {
+			s.memory = append(s.memory, name)
+		}
+	}
+	s.timed = len(ns)
+	if s.timed > 0 {
+		s.meanNs = benchcmp.Mean(ns)
+	}
+	return s
+}
+
+// renderSummary writes s, the summary of the file at path, to w.
+func renderSummary(w io.Writer, path string, s summary) error {
+	fmt.Fprintf(w, "%s: %d benchmarks\n", path, s.count)
+	if s.timed > 0 {
+		fmt.Fprintf(w, "ns/op: min %s (%s), max %s (%s), mean %s over %d benchmarks\n",
+			formatNsOp(s.minNs), s.min, formatNsOp(s.maxNs), s.max, formatNsOp(s.meanNs), s.timed)
+	}
+	fmt.Fprintf(w, "memory: reported by %d of %d benchmarks\n", len(s.memory), s.count)
+	for _, name := range s.memory {
+		fmt.Fprintf(w, "\t%s\n", name)
+	}
+	return nil
+}
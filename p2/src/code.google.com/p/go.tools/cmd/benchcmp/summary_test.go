@@ -0,0 +1,59 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestSummarize(t *testing.T) {
+	defer func(saved *regexp.Regexp) { filterRE = saved }(filterRE)
+
+	const log = `BenchmarkEncrypt	100000000	        19.6 ns/op	       3 B/op	       5 allocs/op
+BenchmarkDecrypt	 5000000	       517 ns/op
+BenchmarkHash	 1000000	      1200 ns/op	       0 allocs/op
+BenchmarkRate	 1000000	      1 items/op
+`
+	l, err := benchcmp.ParseLog(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+
+	filterRE = nil
+	s := summarize(l.Benchmarks)
+	want := summary{
+		count: 4, timed: 3,
+		min: "BenchmarkEncrypt", minNs: 19.6,
+		max: "BenchmarkHash", maxNs: 1200,
+		meanNs: (19.6 + 517 + 1200) / 3,
+		memory: []string{"BenchmarkEncrypt", "BenchmarkHash"},
+	}
+	if !reflect.DeepEqual(want, s) {
+		t.Errorf("summarize: want %+v have %+v", want, s)
+	}
+
+	buf := new(bytes.Buffer)
+	renderSummary(buf, "old.txt", s)
+	wantText := `old.txt: 4 benchmarks
+ns/op: min 19.6 (BenchmarkEncrypt), max 1200 (BenchmarkHash), mean 579 over 3 benchmarks
+memory: reported by 2 of 4 benchmarks
+	BenchmarkEncrypt
+	BenchmarkHash
+`
+	if have := buf.String(); wantText != have {
+		t.Errorf("renderSummary: want\n%s\nhave\n%s", wantText, have)
+	}
+
+	filterRE = regexp.MustCompile("crypt")
+	if s := summarize(l.Benchmarks); s.count != 2 || s.max != "BenchmarkDecrypt" {
+		t.Errorf("summarize with -filter: want 2 benchmarks, max BenchmarkDecrypt have %+v", s)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// testEvent is the part of an event written by go test -json
+// that benchcmp reads.
+type testEvent struct {
+	Action  string
+	Package string
+	Output  string
+}
+
+// parseTestJSON parses the stream of events written by go test -json
+// from r, recovering the benchmark results from the output they carry.
+// A benchmark line may be split across events, so the output of each
+// package is rejoined in full before it is parsed.
+func parseTestJSON(r io.Reader) (*benchcmp.Log, error) {
+	var pkgs []string
+	output := make(map[string]*bytes.Buffer)
+	dec := json.NewDecoder(r)
+	for {
+		var ev testEvent
+		if err := dec.Decode(&ev); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if ev.Action != "output" {
+			continue
+		}
+		buf := output[ev.Package]
+		if buf == nil {
+			buf = new(bytes.Buffer)
+			output[ev.Package] = buf
+			pkgs = append(pkgs, ev.Package)
+		}
+		buf.WriteString(ev.Output)
+	}
+	var all bytes.Buffer
+	for _, pkg := range pkgs {
+		b := output[pkg].Bytes()
+		all.Write(b)
+		if len(b) > 0 && b[len(b)-1] != '\n' {
+			all.WriteByte('\n')
+		}
+	}
+	return benchcmp.ParseLog(&all)
+}
+
+// isTestJSON reports whether the input buffered by br begins, after
+// any white space, with a JSON object, as does the output of go test
+// -json, rather than with the array written by -format=json.
+func isTestJSON(br *bufio.Reader) bool {
+	return firstByte(br) == '{'
+}
@@ -0,0 +1,53 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestParseTestJSON(t *testing.T) {
+	const events = `{"Time":"2014-01-01T00:00:00Z","Action":"start","Package":"crypto/aes"}
+{"Time":"2014-01-01T00:00:00Z","Action":"output","Package":"crypto/aes","Output":"goos: linux\n"}
+{"Time":"2014-01-01T00:00:00Z","Action":"output","Package":"crypto/aes","Output":"pkg: crypto/aes\n"}
+{"Time":"2014-01-01T00:00:00Z","Action":"output","Package":"crypto/aes","Output":"BenchmarkEncrypt-8   \t"}
+{"Time":"2014-01-01T00:00:00Z","Action":"output","Package":"fmt","Output":"BenchmarkSprintf-8 \t 1000\t 120 ns/op"}
+{"Time":"2014-01-01T00:00:01Z","Action":"output","Package":"crypto/aes","Output":"100000000\t        19.6 ns/op\n"}
+{"Time":"2014-01-01T00:00:01Z","Action":"output","Package":"crypto/aes","Output":"PASS\n"}
+{"Time":"2014-01-01T00:00:01Z","Action":"pass","Package":"crypto/aes","Elapsed":1.5}
+`
+	br := bufio.NewReader(strings.NewReader("\n" + events))
+	if !isTestJSON(br) || isJSON(br) {
+		t.Fatalf("go test -json output not detected")
+	}
+	log, err := parseTestJSON(br)
+	if err != nil {
+		t.Fatalf("parseTestJSON failed: %v", err)
+	}
+	if log.GOOS != "linux" || log.Pkg != "crypto/aes" {
+		t.Errorf("parseTestJSON: want goos linux, pkg crypto/aes have %q, %q", log.GOOS, log.Pkg)
+	}
+	bb := log.Benchmarks
+	if len(bb) != 2 || len(bb["BenchmarkEncrypt-8"]) != 1 || len(bb["BenchmarkSprintf-8"]) != 1 {
+		t.Fatalf("parseTestJSON: wrong benchmarks %v", bb)
+	}
+	if b := bb["BenchmarkEncrypt-8"][0]; b.N != 100000000 || b.NsOp != 19.6 {
+		t.Errorf("parseTestJSON: want BenchmarkEncrypt-8 100000000 19.6 ns/op have %v", b)
+	}
+	if len(log.Malformed) > 0 {
+		t.Errorf("parseTestJSON: unexpected malformed lines %v", log.Malformed)
+	}
+
+	if _, err := parseTestJSON(strings.NewReader(`{"Action":"output",`)); err == nil {
+		t.Errorf("parseTestJSON of truncated input should have failed")
+	}
+	for _, in := range []string{"BenchmarkA\t100\t5 ns/op\n", "[]", ""} {
+		if isTestJSON(bufio.NewReader(strings.NewReader(in))) {
+			t.Errorf("isTestJSON(%q) = true, want false", in)
+		}
+	}
+}
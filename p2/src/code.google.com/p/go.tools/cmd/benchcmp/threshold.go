@@ -0,0 +1,181 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// thresholds holds the percent change beyond which a measurement has
+// changed, keyed by metric name. The threshold of any metric not
+// listed is held under the empty name.
+//
+// It is a flag.Value accepting a comma-separated list of
+// metric=percent pairs and, optionally, a bare percent
+// replacing the default: 3,allocs=0,bytes=0.
+type thresholds map[string]float64
+
+// threshold holds the thresholds set by -threshold.
+var threshold = thresholds{"": 5}
+
+// thresholdSet reports whether -threshold was given, in which case the
+// thresholds also apply to -changed.
+var thresholdSet bool
+
+// changedOnly holds the direction of the changes to show, as set by
+// -changed, or "" to show all benchmarks.
+var changedOnly changedFilter
+
+func init() {
+	flag.Var(threshold, "threshold", "percent change beyond which a regression fails -ci, as a default and a list of metric=percent, such as 5,allocs=0")
+	flag.Var(&changedOnly, "changed", "show only benchmarks that have changed: in both directions, or only those beyond -threshold that regressed or improved")
+}
+
+func (t thresholds) String() string {
+	var names []string
+	for name := range t {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	list := []string{strconv.FormatFloat(t[""], 'g', -1, 64)}
+	for _, name := range names {
+		list = append(list, name+"="+strconv.FormatFloat(t[name], 'g', -1, 64))
+	}
+	return strings.Join(list, ",")
+}
+
+func (t thresholds) Set(s string) error {
+	for _, elem := range strings.Split(s, ",") {
+		var name string
+		pct := strings.TrimSpace(elem)
+		if i := strings.Index(pct, "="); i >= 0 {
+			name, pct = strings.TrimSpace(pct[:i]), strings.TrimSpace(pct[i+1:])
+			if !thresholdMetric(name) {
+				return fmt.Errorf("unknown metric %q; valid metrics are %s, their units, or the unit of a custom metric", name, strings.Join(metricNames(), ", "))
+			}
+		}
+		v, err := strconv.ParseFloat(pct, 64)
+		if err != nil || v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+			return fmt.Errorf("invalid threshold %q; want a non-negative percentage", elem)
+		}
+		t[name] = v
+	}
+	thresholdSet = true
+	return nil
+}
+
+// of returns the threshold for the measurement described by s.
+func (t thresholds) of(s section) float64 {
+	for _, name := range []string{s.label, s.unit, shortName(s)} {
+		if v, ok := t[name]; ok {
+			return v
+		}
+	}
+	return t[""]
+}
+
+// shortName returns the unit of s without its "/op" suffix, as in ns for
+// ns/op, which -threshold accepts as the name of a known measurement.
+func shortName(s section) string {
+	return strings.TrimSuffix(s.unit, "/op")
+}
+
+// thresholdMetric reports whether name may be given a threshold: any
+// name -metric accepts, or the short name of a known measurement.
+func thresholdMetric(name string) bool {
+	if validMetric(name) {
+		return true
+	}
+	for _, s := range knownSections() {
+		if name == shortName(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// changed reports whether the change d in the measurement described by
+// s is shown by -changed. With -threshold, changes within the threshold
+// are hidden as well as those that are exactly zero.
+func changed(s section, d benchcmp.Delta) bool {
+	if !thresholdSet {
+		return d.Changed()
+	}
+	return d.ChangedBy(threshold.of(s))
+}
+
+// A changedFilter holds the direction of the changes shown by -changed:
+// both, regressed, or improved. It is a flag.Value that may be given
+// alone, as -changed, to show changes in both directions.
+type changedFilter string
+
+func (f *changedFilter) String() string { return string(*f) }
+
+func (f *changedFilter) IsBoolFlag() bool { return true }
+
+func (f *changedFilter) Set(s string) error {
+	switch s {
+	case "true":
+		s = "both"
+	case "false":
+		s = ""
+	case "both", "regressed", "improved":
+	default:
+		return fmt.Errorf("unknown direction %q; want both, regressed, or improved", s)
+	}
+	*f = changedFilter(s)
+	return nil
+}
+
+// shows reports whether the table for s shows cmp. With -changed=both,
+// it shows the changes that changed reports, and with -changed=regressed
+// or -changed=improved, those that classify, as used by -ci, puts beyond
+// -threshold in that direction.
+func (f changedFilter) shows(s section, cmp benchcmp.BenchCmp) bool {
+	switch f {
+	case "both":
+		return changed(s, s.delta(cmp))
+	case "regressed":
+		return s.classify(cmp, threshold) > 0
+	case "improved":
+		return s.classify(cmp, threshold) < 0
+	}
+	return true
+}
+
+// showsAround returns the benchmarks of cmps that the table for s shows,
+// keyed by their old benchmark: those that f shows and, with -context,
+// up to that many of the others that measured s on either side of each,
+// in parse order. Overlapping windows merge.
+func (f changedFilter) showsAround(s section, cmps []benchcmp.BenchCmp) map[*benchcmp.Bench]bool {
+	var measured []benchcmp.BenchCmp
+	for _, cmp := range cmps {
+		if s.measured(cmp) {
+			measured = append(measured, cmp)
+		}
+	}
+	sort.Stable(benchcmp.ByParseOrder(measured))
+	shown := make(map[*benchcmp.Bench]bool)
+	for i, cmp := range measured {
+		if !f.shows(s, cmp) {
+			continue
+		}
+		for j := i - *contextN; j <= i+*contextN; j++ {
+			if 0 <= j && j < len(measured) {
+				shown[measured[j].Before] = true
+			}
+		}
+	}
+	return shown
+}
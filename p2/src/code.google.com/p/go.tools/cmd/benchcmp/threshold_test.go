@@ -0,0 +1,165 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestThresholdsSet(t *testing.T) {
+	defer func(saved bool) { thresholdSet = saved }(thresholdSet)
+
+	cases := []struct {
+		in   string
+		want thresholds
+	}{
+		{in: "2", want: thresholds{"": 2}},
+		{in: "ns=5,allocs=0,bytes=0", want: thresholds{"": 5, "ns": 5, "allocs": 0, "bytes": 0}},
+		{in: "1.5, B/op = 10, items/op=3", want: thresholds{"": 1.5, "B/op": 10, "items/op": 3}},
+	}
+	for _, tt := range cases {
+		have := thresholds{"": 5}
+		if err := have.Set(tt.in); err != nil {
+			t.Errorf("Set(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.want, have) {
+			t.Errorf("Set(%q): want %v have %v", tt.in, tt.want, have)
+		}
+	}
+
+	for _, in := range []string{"", "x", "-1", "ns=", "alocs=1", "ns=fast", "=3"} {
+		if err := (thresholds{"": 5}).Set(in); err == nil {
+			t.Errorf("Set(%q) should have failed", in)
+		}
+	}
+
+	th := thresholds{"": 5, "allocs": 0, "bytes": 2}
+	if want, have := "5,allocs=0,bytes=2", th.String(); want != have {
+		t.Errorf("String: want %q have %q", want, have)
+	}
+}
+
+func TestThresholdsOf(t *testing.T) {
+	th := thresholds{"": 5, "ns": 2, "allocs/op": 0, "items/op": 1}
+	cases := []struct {
+		s    section
+		want float64
+	}{
+		{sections[0], 2}, // ns/op
+		{sections[1], 5}, // MB/s
+		{sections[2], 0}, // allocs
+		{sections[3], 5}, // bytes
+		{extraSection("items/op"), 1},
+		{timeSection, 5},
+	}
+	for _, tt := range cases {
+		if have := th.of(tt.s); tt.want != have {
+			t.Errorf("threshold of %s: want %g have %g", tt.s.unit, tt.want, have)
+		}
+	}
+}
+
+func TestChanged(t *testing.T) {
+	defer func(saved thresholds, set bool) { threshold, thresholdSet = saved, set }(threshold, thresholdSet)
+
+	ns, allocs := sections[0], sections[2]
+	small := benchcmp.Delta{Before: 100, After: 103}
+	same := benchcmp.Delta{Before: 100, After: 100}
+
+	threshold, thresholdSet = thresholds{"": 5}, false
+	if !changed(ns, small) || changed(ns, same) {
+		t.Errorf("without -threshold, any change should be shown")
+	}
+
+	threshold, thresholdSet = thresholds{"": 5, "allocs": 0}, true
+	if changed(ns, small) {
+		t.Errorf("ns/op change within threshold should be hidden")
+	}
+	if !changed(allocs, small) || changed(allocs, same) {
+		t.Errorf("allocs change beyond zero threshold should be shown")
+	}
+	if faster := (benchcmp.Delta{Before: 100, After: 90}); !changed(ns, faster) {
+		t.Errorf("improvement beyond threshold should be shown")
+	}
+}
+
+func TestChangedFilter(t *testing.T) {
+	defer func(saved thresholds, set bool) { threshold, thresholdSet = saved, set }(threshold, thresholdSet)
+	threshold, thresholdSet = thresholds{"": 5}, false
+
+	ns, mbs := sections[0], sections[1]
+	cmp := func(before, after float64) benchcmp.BenchCmp {
+		return benchcmp.BenchCmp{
+			Before: &benchcmp.Bench{Name: "BenchmarkA", NsOp: before, MbS: before, Measured: benchcmp.NsOp | benchcmp.MbS},
+			After:  &benchcmp.Bench{Name: "BenchmarkA", NsOp: after, MbS: after, Measured: benchcmp.NsOp | benchcmp.MbS},
+		}
+	}
+	slower, faster, small := cmp(100, 110), cmp(100, 90), cmp(100, 103)
+	for _, tt := range []struct {
+		arg                   string
+		slower, faster, small bool // whether ns/op shows each
+	}{
+		{"false", true, true, true},
+		{"true", true, true, true},
+		{"both", true, true, true},
+		{"regressed", true, false, false},
+		{"improved", false, true, false},
+	} {
+		var f changedFilter
+		if err := f.Set(tt.arg); err != nil {
+			t.Fatalf("-changed=%s: %v", tt.arg, err)
+		}
+		if f.shows(ns, slower) != tt.slower || f.shows(ns, faster) != tt.faster || f.shows(ns, small) != tt.small {
+			t.Errorf("-changed=%s: want %v, %v, %v for slower, faster, small", tt.arg, tt.slower, tt.faster, tt.small)
+		}
+	}
+
+	// MB/s regresses when it falls.
+	var f changedFilter
+	f.Set("regressed")
+	if !f.shows(mbs, faster) || f.shows(mbs, slower) {
+		t.Errorf("-changed=regressed: a fall in MB/s should be shown as a regression")
+	}
+	if err := f.Set("worse"); err == nil {
+		t.Errorf("-changed=worse should have failed")
+	}
+}
+
+func TestShowsAround(t *testing.T) {
+	defer func(saved int) { *contextN = saved }(*contextN)
+
+	var cmps []benchcmp.BenchCmp
+	for i, after := range []float64{100, 100, 100, 150, 100, 100, 100, 100, 50, 100} {
+		cmps = append(cmps, benchcmp.BenchCmp{
+			Before: &benchcmp.Bench{Name: fmt.Sprintf("Benchmark%d", i), NsOp: 100, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: fmt.Sprintf("Benchmark%d", i), NsOp: after, Measured: benchcmp.NsOp},
+		})
+	}
+	for _, tt := range []struct {
+		context int
+		want    []int // indexes of cmps shown
+	}{
+		{0, []int{3, 8}},
+		{1, []int{2, 3, 4, 7, 8, 9}},
+		{2, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+	} {
+		*contextN = tt.context
+		shown := changedFilter("both").showsAround(sections[0], cmps)
+		var have []int
+		for i, cmp := range cmps {
+			if shown[cmp.Before] {
+				have = append(have, i)
+			}
+		}
+		if !reflect.DeepEqual(tt.want, have) {
+			t.Errorf("-context=%d: want %v have %v", tt.context, tt.want, have)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// totalBytes returns the sum of the B/op of each side of the benchmarks
+// in cmps that measured B/op in both runs, and how many of them did.
+func totalBytes(cmps []benchcmp.BenchCmp) (benchcmp.Delta, int) {
+	var d benchcmp.Delta
+	var n int
+	for _, cmp := range cmps {
+		if cmp.Before.Measured&cmp.After.Measured&benchcmp.BOp == 0 {
+			continue
+		}
+		d.Before += float64(cmp.Before.BOp)
+		d.After += float64(cmp.After.BOp)
+		n++
+	}
+	return d, n
+}
+
+// renderTotalBytes writes to w the line shown by -totalbytes: the change
+// in the B/op summed over the benchmarks in cmps that measured it in
+// both runs, whether or not -changed shows them, as a quick read on
+// the overall memory pressure. It writes nothing if none measured B/op.
+func renderTotalBytes(w io.Writer, cmps []benchcmp.BenchCmp) {
+	d, n := totalBytes(cmps)
+	if n == 0 {
+		return
+	}
+	fmt.Fprintf(w, "total B/op change: %s→%s (%s)\n", formatCount(d.Before), formatCount(d.After), d.Percent())
+}
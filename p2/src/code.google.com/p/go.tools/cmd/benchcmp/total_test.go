@@ -0,0 +1,49 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestRenderTotalBytes(t *testing.T) {
+	mem := benchcmp.NsOp | benchcmp.BOp
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkA", NsOp: 10, BOp: 100, Measured: mem},
+			After:  &benchcmp.Bench{Name: "BenchmarkA", NsOp: 10, BOp: 150, Measured: mem},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkB", NsOp: 10, BOp: 300, Measured: mem},
+			After:  &benchcmp.Bench{Name: "BenchmarkB", NsOp: 10, BOp: 250, Measured: mem},
+		},
+		{
+			// Only the new run measured B/op, so it is left out.
+			Before: &benchcmp.Bench{Name: "BenchmarkC", NsOp: 10, Measured: benchcmp.NsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkC", NsOp: 10, BOp: 5000, Measured: mem},
+		},
+	}
+	buf := new(bytes.Buffer)
+	renderTotalBytes(buf, cmps)
+	if want, have := "total B/op change: 400→400 (+0.00%)\n", buf.String(); want != have {
+		t.Errorf("renderTotalBytes: want %q have %q", want, have)
+	}
+
+	cmps[1].After.BOp = 450
+	buf.Reset()
+	renderTotalBytes(buf, cmps)
+	if want, have := "total B/op change: 400→600 (+50.00%)\n", buf.String(); want != have {
+		t.Errorf("renderTotalBytes: want %q have %q", want, have)
+	}
+
+	buf.Reset()
+	renderTotalBytes(buf, cmps[2:])
+	if buf.Len() != 0 {
+		t.Errorf("renderTotalBytes without B/op: want no output have %q", buf.String())
+	}
+}
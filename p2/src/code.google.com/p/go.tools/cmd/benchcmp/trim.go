@@ -0,0 +1,64 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sort"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// trimSamples returns the samples of x in increasing order, without the
+// largest pct percent of them, which are the slowest of times such as
+// ns/op. The number discarded is rounded down.
+func trimSamples(x []float64, pct float64) []float64 {
+	s := append([]float64(nil), x...)
+	sort.Float64s(s)
+	return s[:len(s)-trimCount(len(s), pct)]
+}
+
+// trimCount returns the number of n samples that make up pct percent.
+func trimCount(n int, pct float64) int {
+	return int(float64(n) * pct / 100)
+}
+
+// trimRuns returns the runs of each benchmark in bb without the slowest
+// pct percent by ns/op, and the fastest too if fast is set. Benchmarks
+// that did not all record ns/op are left alone.
+func trimRuns(bb benchcmp.BenchSet, pct float64, fast bool) benchcmp.BenchSet {
+	trimmed := make(benchcmp.BenchSet, len(bb))
+	for name, runs := range bb {
+		trimmed[name] = runs
+		ns := make([]float64, len(runs))
+		for i, run := range runs {
+			if run.Measured&benchcmp.NsOp == 0 {
+				ns = nil
+				break
+			}
+			ns[i] = run.NsOp
+		}
+		if len(ns) < 2 {
+			continue
+		}
+		x := trimSamples(ns, pct)
+		if fast {
+			x = x[trimCount(len(ns), pct):]
+		}
+		// Keep the runs with the remaining times, in order.
+		keep := make(map[float64]int)
+		for _, v := range x {
+			keep[v]++
+		}
+		var kept []*benchcmp.Bench
+		for _, run := range runs {
+			if keep[run.NsOp] > 0 {
+				keep[run.NsOp]--
+				kept = append(kept, run)
+			}
+		}
+		trimmed[name] = kept
+	}
+	return trimmed
+}
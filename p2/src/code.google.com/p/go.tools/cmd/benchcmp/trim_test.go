@@ -0,0 +1,65 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestTrimSamples(t *testing.T) {
+	twenty := make([]float64, 20)
+	for i := range twenty {
+		twenty[i] = float64(20 - i)
+	}
+	cases := []struct {
+		x    []float64
+		pct  float64
+		want []float64
+	}{
+		{x: twenty, pct: 10, want: []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18}},
+		{x: []float64{3, 1, 2}, pct: 0, want: []float64{1, 2, 3}},
+		{x: []float64{3, 1, 2}, pct: 10, want: []float64{1, 2, 3}}, // rounds down
+		{x: []float64{3, 1, 300, 2}, pct: 25, want: []float64{1, 2, 3}},
+		{x: []float64{5, 5, 5, 5}, pct: 50, want: []float64{5, 5}},
+	}
+	for _, tt := range cases {
+		if have := trimSamples(tt.x, tt.pct); !reflect.DeepEqual(tt.want, have) {
+			t.Errorf("trimSamples(%v, %g): want %v have %v", tt.x, tt.pct, tt.want, have)
+		}
+	}
+}
+
+func TestTrimRuns(t *testing.T) {
+	run := func(ns float64) *benchcmp.Bench {
+		return &benchcmp.Bench{Name: "BenchmarkA", NsOp: ns, Measured: benchcmp.NsOp}
+	}
+	bb := benchcmp.BenchSet{
+		"BenchmarkA": {run(10), run(90), run(11), run(10), run(1)},
+		"BenchmarkB": {{Name: "BenchmarkB", MbS: 1, Measured: benchcmp.MbS}, {Name: "BenchmarkB", MbS: 2, Measured: benchcmp.MbS}},
+	}
+	ns := func(runs []*benchcmp.Bench) []float64 {
+		var x []float64
+		for _, r := range runs {
+			x = append(x, r.NsOp)
+		}
+		return x
+	}
+
+	slow := trimRuns(bb, 20, false)
+	if want, have := []float64{10, 11, 10, 1}, ns(slow["BenchmarkA"]); !reflect.DeepEqual(want, have) {
+		t.Errorf("trimRuns(slow): want %v have %v", want, have)
+	}
+	if len(slow["BenchmarkB"]) != 2 {
+		t.Errorf("trimRuns trimmed runs without ns/op")
+	}
+
+	both := trimRuns(bb, 20, true)
+	if want, have := []float64{10, 11, 10}, ns(both["BenchmarkA"]); !reflect.DeepEqual(want, have) {
+		t.Errorf("trimRuns(both): want %v have %v", want, have)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// tsvEscaper escapes the characters that may not appear in a field of
+// tab-separated values, as in the linear TSV convention.
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// renderTSV writes cmps to w as tab-separated values, with the header
+// and rows of renderCSV. Rather than quoted, fields are escaped with
+// backslashes, so that each line splits on tabs into its fields. As with
+// renderCSV, the header of -header comes first as comment lines.
+func renderTSV(w io.Writer, cmps []benchcmp.BenchCmp) error {
+	bw := bufio.NewWriter(w)
+	writeCommentHeader(bw)
+	writeTSVRow(bw, csvHeader)
+	for _, row := range csvRows(cmps) {
+		writeTSVRow(bw, row)
+	}
+	return bw.Flush()
+}
+
+// writeTSVRow writes one line of tab-separated fields to w.
+func writeTSVRow(w io.Writer, fields []string) {
+	for i, f := range fields {
+		if i > 0 {
+			io.WriteString(w, "\t")
+		}
+		io.WriteString(w, tsvEscaper.Replace(f))
+	}
+	io.WriteString(w, "\n")
+}
@@ -0,0 +1,39 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestRenderTSV(t *testing.T) {
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkTime", NsOp: 100, Measured: benchcmp.NsOp, Extra: map[string]float64{"items/op": 4}},
+			After:  &benchcmp.Bench{Name: "BenchmarkTime", NsOp: 150, Measured: benchcmp.NsOp, Extra: map[string]float64{"items/op": 5}},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkOdd name,\t\"x\"", NsOp: 0, BOp: 0, Measured: benchcmp.NsOp | benchcmp.BOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkOdd name,\t\"x\"", NsOp: 1.25, BOp: 8, Measured: benchcmp.NsOp | benchcmp.BOp},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := renderTSV(buf, cmps); err != nil {
+		t.Fatalf("renderTSV failed: %v", err)
+	}
+
+	want := "benchmark\tmetric\told\tnew\tdelta\n" +
+		"BenchmarkTime\tns/op\t100\t150\t50.00\n" +
+		"BenchmarkTime\titems/op\t4\t5\t25.00\n" +
+		"BenchmarkOdd name,\\t\"x\"\tns/op\t0\t1.25\t\n" +
+		"BenchmarkOdd name,\\t\"x\"\tB/op\t0\t8\t\n"
+	if have := buf.String(); want != have {
+		t.Errorf("renderTSV incorrect output:\nwant %q\nhave %q", want, have)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// watchInterval is how often -watch polls the new file for changes.
+var watchInterval = 500 * time.Millisecond
+
+// clearScreen is the ANSI escape sequence that clears a terminal and
+// moves the cursor to its top left corner.
+const clearScreen = "\x1b[H\x1b[2J"
+
+// A fileStamp identifies a version of a file by its modification time
+// and size, one of which changes whenever it is rewritten.
+type fileStamp struct {
+	mod  time.Time
+	size int64
+}
+
+// stampOf returns the fileStamp of the named file.
+func stampOf(path string) (fileStamp, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{fi.ModTime(), fi.Size()}, nil
+}
+
+// A watcher polls a file for rewrites.
+type watcher struct {
+	path  string
+	shown fileStamp // stamp of the file when last compared
+	seen  fileStamp // stamp of the file at the last poll
+}
+
+// newWatcher returns a watcher of the named file as it is now.
+func newWatcher(path string) *watcher {
+	st, _ := stampOf(path)
+	return &watcher{path: path, shown: st, seen: st}
+}
+
+// poll reports whether the file has been rewritten since it was last
+// compared. So as not to compare a file that go test is still writing,
+// a rewrite is reported only once the file is unchanged since the
+// previous poll.
+func (w *watcher) poll() bool {
+	st, err := stampOf(w.path)
+	if err != nil {
+		return false
+	}
+	settled := st == w.seen
+	w.seen = st
+	if settled && st != w.shown {
+		w.shown = st
+		return true
+	}
+	return false
+}
+
+// watchFiles compares the benchmarks in oldPath and newPath, writing
+// the comparison to stdout using render, and compares them again,
+// clearing the screen first, each time newPath is rewritten. It does
+// not return. Errors, such as a new file with no benchmarks yet or one
+// removed since it was polled, are reported and the watch goes on.
+func watchFiles(oldPath, newPath string, render benchcmp.Renderer) {
+	w := newWatcher(newPath)
+	for first := true; ; first = false {
+		if !first {
+			for !w.poll() {
+				time.Sleep(watchInterval)
+			}
+		}
+		fmt.Fprint(stdout, clearScreen)
+		if err := watchCompare(oldPath, newPath, render); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
+
+// watchCompare writes the comparison of the benchmarks in oldPath and
+// newPath to stdout using render, for watchFiles. Unlike compare, it
+// returns an error rather than exiting if either file cannot be read.
+func watchCompare(oldPath, newPath string, render benchcmp.Renderer) error {
+	logs, err := readLogs(oldPath, newPath)
+	if err != nil {
+		return err
+	}
+	cmps, err := compareLogs(oldPath, newPath, logs)
+	if err != nil {
+		return errors.New("benchcmp: " + err.Error())
+	}
+	output(render, cmps)
+	return nil
+}
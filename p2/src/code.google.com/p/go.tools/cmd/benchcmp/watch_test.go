@@ -0,0 +1,91 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestWatcherPoll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchcmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "new.txt")
+	write := func(data string, mod time.Time) {
+		if err := ioutil.WriteFile(path, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Unix(1400000000, 0)
+	write("BenchmarkA\t1\t1 ns/op\n", start)
+
+	w := newWatcher(path)
+	if w.poll() {
+		t.Errorf("poll of an unchanged file reported a rewrite")
+	}
+	write("BenchmarkA\t1\t2 ns/op\nBenchmarkB", start.Add(time.Second))
+	if w.poll() {
+		t.Errorf("poll reported a rewrite that may still be in progress")
+	}
+	if !w.poll() {
+		t.Errorf("poll did not report a finished rewrite")
+	}
+	if w.poll() {
+		t.Errorf("poll reported the same rewrite twice")
+	}
+
+	os.Remove(path)
+	if w.poll() || w.poll() {
+		t.Errorf("poll of a removed file reported a rewrite")
+	}
+}
+
+func TestWatchCompare(t *testing.T) {
+	defer func(saved io.Writer) { stderr = saved }(stderr)
+	stderr = new(bytes.Buffer)
+
+	dir, err := ioutil.TempDir("", "benchcmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	oldPath, newPath := filepath.Join(dir, "old.txt"), filepath.Join(dir, "new.txt")
+	for _, path := range []string{oldPath, newPath} {
+		if err := ioutil.WriteFile(path, []byte("BenchmarkA\t1\t1 ns/op\n"), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	render := benchcmp.RendererFunc(renderJSON)
+
+	restore := discardOutput(t)
+	defer restore()
+	if err := watchCompare(oldPath, newPath, render); err != nil {
+		t.Errorf("watchCompare failed: %v", err)
+	}
+
+	// A new file that vanishes before it is read is reported, rather
+	// than ending the watch.
+	os.Remove(newPath)
+	if err := watchCompare(oldPath, newPath, render); err == nil {
+		t.Errorf("watchCompare of a removed file: want error")
+	}
+	ioutil.WriteFile(newPath, nil, 0666)
+	if err := watchCompare(oldPath, newPath, render); err == nil {
+		t.Errorf("watchCompare of an empty file: want error")
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+// renderYAML writes cmps to w as a YAML mapping: the jsonReport written
+// by renderJSON, encoded under the same names, so that the two formats
+// share one schema.
+func renderYAML(w io.Writer, cmps []benchcmp.BenchCmp) error {
+	bw := bufio.NewWriter(w)
+	writeYAML(bw, reflect.ValueOf(newJSONReport(cmps)), "", "")
+	return bw.Flush()
+}
+
+// writeYAML writes to w the YAML block mapping of v, a struct or a map
+// with string keys, whose values are structs, maps, pointers to them,
+// slices of structs, strings, ints, or float64s. Struct fields are
+// named, and omitted, as by their json tags. The first line of the
+// mapping is preceded by first and the others by indent.
+func writeYAML(w io.Writer, v reflect.Value, first, indent string) {
+	prefix := first
+	entry := func(key string, val reflect.Value) {
+		for val.Kind() == reflect.Ptr {
+			val = val.Elem()
+		}
+		switch val.Kind() {
+		case reflect.Struct, reflect.Map:
+			fmt.Fprintf(w, "%s%s:\n", prefix, yamlString(key))
+			writeYAML(w, val, indent+"  ", indent+"  ")
+		case reflect.Slice:
+			if val.Len() == 0 {
+				fmt.Fprintf(w, "%s%s: []\n", prefix, yamlString(key))
+				break
+			}
+			fmt.Fprintf(w, "%s%s:\n", prefix, yamlString(key))
+			for i := 0; i < val.Len(); i++ {
+				writeYAML(w, val.Index(i), indent+"  - ", indent+"    ")
+			}
+		case reflect.Int:
+			fmt.Fprintf(w, "%s%s: %d\n", prefix, yamlString(key), val.Int())
+		case reflect.Float64:
+			fmt.Fprintf(w, "%s%s: %s\n", prefix, yamlString(key), strconv.FormatFloat(val.Float(), 'g', -1, 64))
+		default:
+			fmt.Fprintf(w, "%s%s: %s\n", prefix, yamlString(key), yamlString(val.String()))
+		}
+		prefix = indent
+	}
+
+	if v.Kind() == reflect.Map {
+		var keys []string
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			entry(k, v.MapIndex(reflect.ValueOf(k)))
+		}
+		return
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("json"), ",")
+		f := v.Field(i)
+		omitEmpty := len(tag) > 1 && tag[1] == "omitempty"
+		if omitEmpty && (f.Kind() == reflect.Ptr && f.IsNil() || (f.Kind() == reflect.Map || f.Kind() == reflect.Slice) && f.Len() == 0) {
+			continue
+		}
+		entry(tag[0], f)
+	}
+}
+
+// yamlString returns s as a YAML scalar: plain if it cannot be mistaken
+// for anything but a string, and double-quoted otherwise.
+func yamlString(s string) string {
+	if s == "" || !isLetter(s[0]) {
+		return strconv.Quote(s)
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !isLetter(c) && !('0' <= c && c <= '9') && !strings.ContainsRune("_./=-", rune(c)) {
+			return strconv.Quote(s)
+		}
+	}
+	switch strings.ToLower(s) {
+	case "y", "n", "yes", "no", "on", "off", "true", "false", "null":
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,92 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"code.google.com/p/go.tools/benchcmp"
+)
+
+func TestRenderYAML(t *testing.T) {
+	cmps := []benchcmp.BenchCmp{
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkEncrypt/size=1k-8", NsOp: 100, MbS: 10, Measured: benchcmp.NsOp | benchcmp.MbS},
+			After:  &benchcmp.Bench{Name: "BenchmarkEncrypt/size=1k-8", NsOp: 50, MbS: 20, Measured: benchcmp.NsOp | benchcmp.MbS},
+		},
+		{
+			Before: &benchcmp.Bench{Name: "BenchmarkDecrypt: cold", AllocsOp: 0, Extra: map[string]float64{"items/op": 4, "req/s": 10}, Measured: benchcmp.AllocsOp},
+			After:  &benchcmp.Bench{Name: "BenchmarkDecrypt: cold", AllocsOp: 2, Extra: map[string]float64{"items/op": 4, "req/s": 12.5}, Measured: benchcmp.AllocsOp},
+		},
+	}
+	buf := new(bytes.Buffer)
+	if err := renderYAML(buf, cmps); err != nil {
+		t.Fatalf("renderYAML failed: %v", err)
+	}
+	want := `benchmarks:
+  - name: BenchmarkEncrypt/size=1k-8
+    ns_op:
+      before: 100
+      after: 50
+      delta_percent: -50
+    mb_s:
+      before: 10
+      after: 20
+      speedup: 2
+  - name: "BenchmarkDecrypt: cold"
+    allocs_op:
+      before: 0
+      after: 2
+    extra:
+      items/op:
+        before: 4
+        after: 4
+        delta_percent: 0
+      req/s:
+        before: 10
+        after: 12.5
+        delta_percent: 25
+`
+	if have := trimYAMLHeader(t, buf.String()); want != have {
+		t.Errorf("renderYAML: want\n%s\nhave\n%s", want, have)
+	}
+
+	buf.Reset()
+	renderYAML(buf, nil)
+	if have := trimYAMLHeader(t, buf.String()); have != "benchmarks: []\n" {
+		t.Errorf("renderYAML of no benchmarks: want benchmarks: []\\n have %q", have)
+	}
+}
+
+// trimYAMLHeader returns out, the output of renderYAML, without the
+// version and generation time that begin it.
+func trimYAMLHeader(t *testing.T, out string) string {
+	lines := strings.SplitAfterN(out, "\n", 3)
+	if len(lines) < 3 || lines[0] != "version: 1\n" || !strings.HasPrefix(lines[1], `generated: "`) {
+		t.Errorf("renderYAML: output lacks version and generation time:\n%s", out)
+		return out
+	}
+	return lines[2]
+}
+
+func TestYAMLString(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{"BenchmarkA-8", "BenchmarkA-8"},
+		{"ns/op", "ns/op"},
+		{"", `""`},
+		{"true", `"true"`},
+		{"No", `"No"`},
+		{"1234", `"1234"`},
+		{"a: b", `"a: b"`},
+		{"-x", `"-x"`},
+		{`say "hi"`, `"say \"hi\""`},
+	} {
+		if have := yamlString(tt.in); have != tt.want {
+			t.Errorf("yamlString(%q): want %s have %s", tt.in, tt.want, have)
+		}
+	}
+}